@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Condition types.
+const (
+	// TypeTransform indicates whether the transform of a DataSource is
+	// valid.
+	TypeTransform xpv1.ConditionType = "Transform"
+
+	// TypeStale indicates whether the data of a DataSource was kept
+	// because its source was unavailable.
+	TypeStale xpv1.ConditionType = "Stale"
+)
+
+// Condition reasons.
+const (
+	ReasonTransformCompiled xpv1.ConditionReason = "Compiled"
+	ReasonTransformInvalid  xpv1.ConditionReason = "CompileError"
+
+	ReasonRefreshed         xpv1.ConditionReason = "Refreshed"
+	ReasonSourceUnavailable xpv1.ConditionReason = "SourceUnavailable"
+	ReasonStaleExpired      xpv1.ConditionReason = "Expired"
+)
+
+// TransformCompiled returns a condition indicating that the transform of a
+// DataSource was compiled successfully.
+func TransformCompiled() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTransform,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTransformCompiled,
+	}
+}
+
+// TransformInvalid returns a condition indicating that the transform of a
+// DataSource could not be compiled.
+func TransformInvalid(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTransform,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTransformInvalid,
+		Message:            err.Error(),
+	}
+}
+
+// Refreshed returns a condition indicating that the data of a DataSource was
+// successfully looked up from its source.
+func Refreshed() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeStale,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRefreshed,
+	}
+}
+
+// SourceUnavailable returns a condition indicating that the data of a
+// DataSource was kept because its source was unavailable.
+func SourceUnavailable(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeStale,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSourceUnavailable,
+		Message:            err.Error(),
+	}
+}
+
+// StaleExpired returns a condition indicating that the data of a DataSource
+// is older than its max staleness.
+func StaleExpired(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeStale,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonStaleExpired,
+		Message:            err.Error(),
+	}
+}
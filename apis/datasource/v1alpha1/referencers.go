@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	errGetDataSource   = "cannot get referenced DataSource"
+	errListDataSources = "cannot list referenced DataSources"
+	errFmtVariable     = "cannot resolve variable %s"
+	errFmtCycle        = "DataSource references form a cycle: %s"
+)
+
+// ResolveReferences of this DataSource. Variables are always resolved again,
+// rather than only when they have no value, so that they track changes to
+// the data of the DataSources they reference.
+func (mg *DataSource) ResolveReferences(ctx context.Context, c client.Reader) error {
+	if err := checkCycles(ctx, c, mg); err != nil {
+		return err
+	}
+
+	r := reference.NewAPIResolver(c, mg)
+	for i := range mg.Spec.ForProvider.Variables {
+		v := &mg.Spec.ForProvider.Variables[i]
+		rsp, err := r.Resolve(ctx, reference.ResolutionRequest{
+			Reference: v.DataSourceRef,
+			Selector:  v.DataSourceSelector,
+			To:        reference.To{Managed: &DataSource{}, List: &DataSourceList{}},
+			Extract:   AtProviderValue(v.FieldPath),
+		})
+		if err != nil {
+			return errors.Wrapf(err, errFmtVariable, v.Name)
+		}
+		if rsp.ResolvedReference == nil {
+			continue
+		}
+		value := rsp.ResolvedValue
+		v.Value = &value
+		v.DataSourceRef = rsp.ResolvedReference
+	}
+	return nil
+}
+
+// AtProviderValue returns an ExtractValueFn that returns the value at the
+// supplied path of the data of a DataSource. Values that are not strings are
+// returned as JSON, and an empty string is returned if there is no value.
+func AtProviderValue(path string) reference.ExtractValueFn {
+	return func(mg resource.Managed) string {
+		ds, ok := mg.(*DataSource)
+		if !ok || ds.Status.AtProvider == nil {
+			return ""
+		}
+		var data interface{}
+		if err := json.Unmarshal(ds.Status.AtProvider.Raw, &data); err != nil {
+			return ""
+		}
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v, err := fieldpath.Pave(obj).GetValue(path)
+		if err != nil {
+			return ""
+		}
+		if s, ok := v.(string); ok {
+			return s
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// checkCycles returns an error if the DataSources referenced by the supplied
+// DataSource, directly or through other DataSources, include itself.
+func checkCycles(ctx context.Context, c client.Reader, ds *DataSource) error {
+	visited := map[string]bool{}
+	var visit func(ds *DataSource, path []string) error
+	visit = func(ds *DataSource, path []string) error {
+		names, err := referencedNames(ctx, c, ds)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			p := append(path[:len(path):len(path)], name)
+			if name == path[0] {
+				return errors.Errorf(errFmtCycle, strings.Join(p, " -> "))
+			}
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			next := &DataSource{}
+			if err := c.Get(ctx, types.NamespacedName{Name: name}, next); err != nil {
+				if resource.IgnoreNotFound(err) == nil {
+					continue
+				}
+				return errors.Wrap(err, errGetDataSource)
+			}
+			if err := visit(next, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit(ds, []string{ds.GetName()})
+}
+
+// referencedNames returns the names of the DataSources the variables of the
+// supplied DataSource may reference: the referenced DataSource if a variable
+// has a reference, or else every DataSource its selector matches.
+func referencedNames(ctx context.Context, c client.Reader, ds *DataSource) ([]string, error) {
+	names := []string{}
+	for _, v := range ds.Spec.ForProvider.Variables {
+		switch {
+		case v.DataSourceRef != nil:
+			names = append(names, v.DataSourceRef.Name)
+		case v.DataSourceSelector != nil:
+			l := &DataSourceList{}
+			if err := c.List(ctx, l, client.MatchingLabels(v.DataSourceSelector.MatchLabels)); err != nil {
+				return nil, errors.Wrap(err, errListDataSources)
+			}
+			for _, to := range l.Items {
+				names = append(names, to.GetName())
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
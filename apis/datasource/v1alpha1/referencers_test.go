@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestResolveReferences(t *testing.T) {
+	dataSource := func(name, data string, refs ...string) *DataSource {
+		ds := &DataSource{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if data != "" {
+			ds.Status.AtProvider = &runtime.RawExtension{Raw: []byte(data)}
+		}
+		for _, r := range refs {
+			ds.Spec.ForProvider.Variables = append(ds.Spec.ForProvider.Variables, Variable{
+				Name:          r,
+				FieldPath:     "value",
+				DataSourceRef: &xpv1.Reference{Name: r},
+			})
+		}
+		return ds
+	}
+
+	// The DataSources that exist, by name.
+	existing := map[string]*DataSource{
+		"region": dataSource("region", `{"value":"eu-west-1","zones":["a","b"]}`),
+		"a":      dataSource("a", `{"value":"a"}`, "b"),
+		"b":      dataSource("b", `{"value":"b"}`, "a"),
+	}
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			ds, ok := existing[key.Name]
+			if !ok {
+				return errors.New("not found")
+			}
+			ds.DeepCopyInto(obj.(*DataSource))
+			return nil
+		},
+	}
+
+	type want struct {
+		vars []Variable
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     *DataSource
+		want   want
+	}{
+		"Resolved": {
+			reason: "Variables should be resolved from the data of the referenced DataSource, with values that are not strings as JSON.",
+			mg: &DataSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Spec: DataSourceSpec{ForProvider: DataSourceParameters{Variables: []Variable{
+					{Name: "region", FieldPath: "value", DataSourceRef: &xpv1.Reference{Name: "region"}, Value: pointer.StringPtr("us-east-1")},
+					{Name: "zones", FieldPath: "zones", DataSourceRef: &xpv1.Reference{Name: "region"}},
+				}}},
+			},
+			want: want{
+				vars: []Variable{
+					{Name: "region", FieldPath: "value", DataSourceRef: &xpv1.Reference{Name: "region"}, Value: pointer.StringPtr("eu-west-1")},
+					{Name: "zones", FieldPath: "zones", DataSourceRef: &xpv1.Reference{Name: "region"}, Value: pointer.StringPtr(`["a","b"]`)},
+				},
+			},
+		},
+		"NoValue": {
+			reason: "Variables referencing missing fields should return an error.",
+			mg: &DataSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "config"},
+				Spec: DataSourceSpec{ForProvider: DataSourceParameters{Variables: []Variable{
+					{Name: "region", FieldPath: "missing", DataSourceRef: &xpv1.Reference{Name: "region"}},
+				}}},
+			},
+			want: want{
+				vars: []Variable{
+					{Name: "region", FieldPath: "missing", DataSourceRef: &xpv1.Reference{Name: "region"}},
+				},
+				err: errors.Wrapf(errors.New("referenced field was empty (referenced resource may not yet be ready)"), errFmtVariable, "region"),
+			},
+		},
+		"Cycle": {
+			reason: "References that form a cycle should return an error.",
+			mg:     dataSource("a", "", "b"),
+			want: want{
+				vars: dataSource("a", "", "b").Spec.ForProvider.Variables,
+				err:  errors.Errorf(errFmtCycle, "a -> b -> a"),
+			},
+		},
+		"SelfReference": {
+			reason: "A DataSource referencing itself should return an error.",
+			mg:     dataSource("self", "", "self"),
+			want: want{
+				vars: dataSource("self", "", "self").Spec.ForProvider.Variables,
+				err:  errors.Errorf(errFmtCycle, "self -> self"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.mg.ResolveReferences(context.Background(), kube)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.vars, tc.mg.Spec.ForProvider.Variables); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
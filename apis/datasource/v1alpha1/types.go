@@ -26,18 +26,327 @@ import (
 
 // SourceType is the type of external data source to retrieve
 // values from.
-// +kubebuilder:validation:Enum=configmap;url
+// +kubebuilder:validation:Enum=configmap;secret;url;kubernetes
 type SourceType string
 
 // SourceTypeConfigMap is a Config Map Source
 const SourceTypeConfigMap SourceType = "configmap"
 
+// SourceTypeSecret is a Secret Source
+const SourceTypeSecret SourceType = "secret"
+
 // SourceTypeURL is a URL
 const SourceTypeURL SourceType = "url"
 
-// DataSourceParameters are the configurable fields of a DataSource.
-type DataSourceParameters struct {
-	SourceType SourceType `json:"type"`
+// SourceTypeKubernetes is an arbitrary Kubernetes object
+const SourceTypeKubernetes SourceType = "kubernetes"
+
+// ListFormat is the format in which the results of a selector lookup are
+// returned.
+// +kubebuilder:validation:Enum=array;map
+type ListFormat string
+
+// ListFormatArray returns results as an array ordered by object name
+const ListFormatArray ListFormat = "array"
+
+// ListFormatMap returns results as a map keyed by object name
+const ListFormatMap ListFormat = "map"
+
+// Format is the format of looked up data.
+// +kubebuilder:validation:Enum=auto;json;yaml;toml;ini;dotenv;properties;csv;xml;html;raw
+type Format string
+
+// Supported formats.
+const (
+	// FormatAuto detects the format of data from the Content-Type of URL
+	// responses, or from the file extension of ConfigMap keys.
+	FormatAuto Format = "auto"
+
+	// FormatJSON parses JSON documents.
+	FormatJSON Format = "json"
+
+	// FormatYAML parses YAML documents. Streams of several documents are
+	// parsed into an array.
+	FormatYAML Format = "yaml"
+
+	// FormatTOML parses TOML documents.
+	FormatTOML Format = "toml"
+
+	// FormatINI parses INI files into an object with a key for each
+	// section. Keys outside of any section are stored at the top level.
+	FormatINI Format = "ini"
+
+	// FormatDotenv parses .env files into an object.
+	FormatDotenv Format = "dotenv"
+
+	// FormatProperties parses Java .properties files into an object.
+	FormatProperties Format = "properties"
+
+	// FormatCSV parses CSV files with a header row into an array of
+	// objects keyed by column name.
+	FormatCSV Format = "csv"
+
+	// FormatXML converts XML documents into objects, as configured by the
+	// xml options.
+	FormatXML Format = "xml"
+
+	// FormatHTML selects values from HTML documents using the selectors
+	// configured by the html options.
+	FormatHTML Format = "html"
+
+	// FormatRaw stores data without parsing it, as configured by the raw
+	// options.
+	FormatRaw Format = "raw"
+)
+
+// Coercion configures how the string values of ConfigMaps are converted into
+// typed values.
+// +kubebuilder:validation:Enum=none;scalars;documents
+type Coercion string
+
+// Supported coercions.
+const (
+	// CoercionNone leaves values as strings.
+	CoercionNone Coercion = "none"
+
+	// CoercionScalars converts values that are JSON numbers, booleans or
+	// null, such as "3" or "true", into numbers, booleans or null.
+	CoercionScalars Coercion = "scalars"
+
+	// CoercionDocuments converts scalars, and also parses values that are
+	// JSON objects or arrays, or multi-line YAML documents, into nested
+	// objects or arrays.
+	CoercionDocuments Coercion = "documents"
+)
+
+// KeyOptions configure which keys of a ConfigMap are stored, and under what
+// names. Keys are filtered, then their prefix is stripped, then they are
+// renamed, and finally they are nested.
+type KeyOptions struct {
+	// Include only keys matching any of these glob patterns, such as
+	// 'db.*'. All keys are included if none are specified.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude keys matching any of these glob patterns, even if they are
+	// included.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// StripPrefix removes this prefix from keys that have it.
+	// +optional
+	StripPrefix *string `json:"stripPrefix,omitempty"`
+
+	// Rename keys, after any prefix has been stripped. Keys are the current
+	// names and values the new names.
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+
+	// Nest expands keys containing the separator into nested objects, such
+	// that 'db.host' and 'db.port' become an object 'db' with keys 'host'
+	// and 'port'.
+	// +optional
+	Nest bool `json:"nest,omitempty"`
+
+	// Separator of nested keys. Defaults to '.'.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+}
+
+// A ConnectionDetail publishes a field of the looked up data as a connection
+// detail of the DataSource.
+type ConnectionDetail struct {
+	// Name of the connection secret key the field is published as.
+	Name string `json:"name"`
+
+	// FromFieldPath is the path of the field within the stored data, for
+	// example 'db.password' or 'endpoints[0].url'. Fields that are strings
+	// are published as-is, while all other fields are published as JSON.
+	FromFieldPath string `json:"fromFieldPath"`
+}
+
+// RawOptions configure how unparsed data is stored. Data is stored as an
+// object with its 'contentType' and 'length' in bytes, and either its 'text',
+// its 'lines', or, if it is not valid UTF-8 text, its 'base64' encoding.
+type RawOptions struct {
+	// TrimSpace removes leading and trailing whitespace from text, and from
+	// each line when lines are split.
+	// +optional
+	TrimSpace bool `json:"trimSpace,omitempty"`
+
+	// SplitLines stores text as an array of its non-empty lines.
+	// +optional
+	SplitLines bool `json:"splitLines,omitempty"`
+}
+
+// XMLOptions configure how XML documents are converted into objects. Each
+// element becomes a key of its parent's object, and elements that are
+// repeated become arrays. Elements with neither attributes nor child elements
+// become strings.
+type XMLOptions struct {
+	// AttributePrefix is prepended to the keys of attributes. Defaults to
+	// '@'.
+	// +optional
+	AttributePrefix *string `json:"attributePrefix,omitempty"`
+
+	// TextKey is the key of the text content of elements that also have
+	// attributes or child elements. Defaults to '#text'.
+	// +optional
+	TextKey *string `json:"textKey,omitempty"`
+}
+
+// HTMLOptions configure how values are selected from HTML documents.
+type HTMLOptions struct {
+	// Selectors of the values to store, keyed by output key.
+	Selectors map[string]HTMLSelector `json:"selectors"`
+}
+
+// An HTMLSelector selects values from an HTML document. Exactly one of xpath
+// or css must be specified.
+type HTMLSelector struct {
+	// XPath expression selecting elements.
+	// +optional
+	XPath *string `json:"xpath,omitempty"`
+
+	// CSS selector selecting elements.
+	// +optional
+	CSS *string `json:"css,omitempty"`
+
+	// Attribute of the selected elements to store. Their text content is
+	// stored if it is not specified.
+	// +optional
+	Attribute *string `json:"attribute,omitempty"`
+
+	// All stores the values of all selected elements as an array, rather
+	// than the value of the first selected element.
+	// +optional
+	All bool `json:"all,omitempty"`
+}
+
+// ExpressionLanguage is the language of extraction expressions.
+// +kubebuilder:validation:Enum=jsonpath;jmespath
+type ExpressionLanguage string
+
+// ExpressionLanguageJSONPath uses Kubernetes style JSONPath expressions
+const ExpressionLanguageJSONPath ExpressionLanguage = "jsonpath"
+
+// ExpressionLanguageJMESPath uses JMESPath expressions
+const ExpressionLanguageJMESPath ExpressionLanguage = "jmespath"
+
+// Extract selects the parts of the looked up data that are stored.
+type Extract struct {
+	// Language of the expressions. Defaults to 'jsonpath'.
+	// +optional
+	Language *ExpressionLanguage `json:"language,omitempty"`
+
+	// Expression selecting the data to store.
+	// +optional
+	Expression *string `json:"expression,omitempty"`
+
+	// Expressions selecting the data to store under each key of an object.
+	// Either expression or expressions must be specified.
+	// +optional
+	Expressions map[string]string `json:"expressions,omitempty"`
+}
+
+// A KeyReference selects a key of a ConfigMap or Secret in the Namespace
+// configured on the current ProviderConfig.
+type KeyReference struct {
+	// Name of the ConfigMap or Secret.
+	Name string `json:"name"`
+
+	// Key within the ConfigMap or Secret.
+	Key string `json:"key"`
+}
+
+// A ValueSource is a ConfigMap or Secret key from which a value is read.
+// Exactly one of ConfigMapKeyRef or SecretKeyRef must be specified.
+type ValueSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap.
+	// +optional
+	ConfigMapKeyRef *KeyReference `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of a Secret.
+	// +optional
+	SecretKeyRef *KeyReference `json:"secretKeyRef,omitempty"`
+}
+
+// A HeaderSource sets a request header to a value read from a ConfigMap or
+// Secret.
+type HeaderSource struct {
+	// Name of the header.
+	Name string `json:"name"`
+
+	// ValueFrom is the source of the header's value.
+	ValueFrom ValueSource `json:"valueFrom"`
+}
+
+// An HTTPRequest configures the request made to a URL.
+type HTTPRequest struct {
+	// Method of the request. Defaults to GET.
+	// +kubebuilder:validation:Enum=GET;POST;PUT;PATCH
+	// +optional
+	Method *string `json:"method,omitempty"`
+
+	// Headers to send with the request.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// HeadersFrom are headers to send with the request whose values are
+	// read from ConfigMaps or Secrets. They take precedence over headers.
+	// +optional
+	HeadersFrom []HeaderSource `json:"headersFrom,omitempty"`
+
+	// QueryParameters to add to the URL of the request.
+	// +optional
+	QueryParameters map[string]string `json:"queryParameters,omitempty"`
+
+	// Body of the request, e.g. a JSON or GraphQL query. The Content-Type
+	// header defaults to 'application/json' when a body is sent.
+	// +optional
+	Body *string `json:"body,omitempty"`
+
+	// BodyFrom reads the body of the request from a ConfigMap or Secret.
+	// It takes precedence over body.
+	// +optional
+	BodyFrom *ValueSource `json:"bodyFrom,omitempty"`
+}
+
+// A KubernetesObject identifies an object in the current Kubernetes cluster.
+type KubernetesObject struct {
+	// APIVersion of the object, e.g. 'v1' or 'apps/v1'.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object, e.g. 'Service'.
+	Kind string `json:"kind"`
+
+	// Name of the object. Either a name or a selector must be specified.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Selector selects all objects of the supplied kind with matching
+	// labels. Either a name or a selector must be specified.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Namespace of the object. Defaults to the Namespace configured on
+	// the current ProviderConfig, and is ignored for cluster scoped kinds.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// FieldPath selects a sub-tree of the object to retrieve, e.g.
+	// 'spec.ports[0]'. The whole object is retrieved if omitted. When a
+	// selector is used the field path applies to each matching object.
+	// +optional
+	FieldPath *string `json:"fieldPath,omitempty"`
+}
+
+// SourceParameters identify a source to look up data from.
+type SourceParameters struct {
+	// SourceType is the type of the source. Required unless sources are
+	// specified.
+	// +optional
+	SourceType SourceType `json:"type,omitempty"`
 
 	// ConfigMapName is the name of a Kubernetes ConfigMap to look up
 	// in the Namespace configured on the current ProviderConfig, when
@@ -45,16 +354,305 @@ type DataSourceParameters struct {
 	// +optional
 	ConfigMapName *string `json:"configMapName,omitempty"`
 
+	// ConfigMapSelector selects all ConfigMaps with matching labels in the
+	// Namespace configured on the current ProviderConfig, when type is
+	// 'configmap' and no configMapName is specified
+	// +optional
+	ConfigMapSelector *metav1.LabelSelector `json:"configMapSelector,omitempty"`
+
+	// SecretName is the name of a Kubernetes Secret to look up in the
+	// Namespace configured on the current ProviderConfig, when type is
+	// 'secret'
+	// +optional
+	SecretName *string `json:"secretName,omitempty"`
+
+	// ExposedKeys are the keys of the Secret whose decoded values are
+	// written to the status of this DataSource, when type is 'secret'.
+	// All other keys are only published as connection details.
+	// +optional
+	ExposedKeys []string `json:"exposedKeys,omitempty"`
+
 	// URL is the URL of a JSON endpint to retrieve data from, when type
 	// is 'url'
 	// +optional
 	URL *string `json:"url,omitempty"`
+
+	// Request configures the HTTP request made to the URL, when type is
+	// 'url'. A GET request is made if omitted.
+	// +optional
+	Request *HTTPRequest `json:"request,omitempty"`
+
+	// BypassCache disables the provider-wide cache of URL responses for
+	// this DataSource, so that every lookup makes a new request, when type
+	// is 'url'
+	// +optional
+	BypassCache bool `json:"bypassCache,omitempty"`
+
+	// Object identifies a Kubernetes object to retrieve data from, when
+	// type is 'kubernetes'
+	// +optional
+	Object *KubernetesObject `json:"object,omitempty"`
+}
+
+// DataSourceParameters are the configurable fields of a DataSource.
+type DataSourceParameters struct {
+	SourceParameters `json:",inline"`
+
+	// Sources is an ordered list of sources to look up data from, in place
+	// of the source identified by type. Each source is tried in turn, and
+	// the data of the first that is successfully looked up is used. The
+	// type and other source fields must not be specified alongside it.
+	// +optional
+	Sources []SourceParameters `json:"sources,omitempty"`
+
+	// Merge the data of all sources into one document, rather than using
+	// the first source that is successfully looked up.
+	// +optional
+	Merge *MergeOptions `json:"merge,omitempty"`
+
+	// Variables read values from the data of other DataSources. They can be
+	// used in the url, request headers and configMapName of sources as Go
+	// templates, e.g. 'https://api.example.org/{{ .region }}/config'.
+	// +optional
+	Variables []Variable `json:"variables,omitempty"`
+
+	// RefreshInterval is how often the data is refreshed from its source,
+	// e.g. '30s' or '24h'. Defaults to the provider's poll interval.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// RefreshSchedule is a cron expression, e.g. '0 6 * * *', describing
+	// when the data is refreshed from its source. Takes precedence over
+	// refreshInterval.
+	// +optional
+	RefreshSchedule *string `json:"refreshSchedule,omitempty"`
+
+	// ListFormat configures how the objects matched by a selector are
+	// returned; either as an 'array' ordered by name, or as a 'map' keyed
+	// by name. Defaults to 'array'.
+	// +optional
+	ListFormat *ListFormat `json:"listFormat,omitempty"`
+
+	// Format of the looked up data. URL responses are parsed as 'json' by
+	// default. When set for a configmap source, each value of the ConfigMap
+	// is parsed in this format, and 'auto' parses only values whose keys
+	// have a recognised file extension, such as app.yaml.
+	// +optional
+	Format *Format `json:"format,omitempty"`
+
+	// XML configures how XML documents are converted into objects.
+	// +optional
+	XML *XMLOptions `json:"xml,omitempty"`
+
+	// HTML configures how values are selected from HTML documents.
+	// +optional
+	HTML *HTMLOptions `json:"html,omitempty"`
+
+	// Raw configures how unparsed data is stored.
+	// +optional
+	Raw *RawOptions `json:"raw,omitempty"`
+
+	// Keys configures which keys of ConfigMaps are stored, and under what
+	// names.
+	// +optional
+	Keys *KeyOptions `json:"keys,omitempty"`
+
+	// Coercion converts the string values of ConfigMaps that are not parsed
+	// in another format into typed values. Defaults to 'none'.
+	// +optional
+	Coercion *Coercion `json:"coercion,omitempty"`
+
+	// Extract selects the parts of the looked up data that are stored,
+	// whatever the type of the source.
+	// +optional
+	Extract *Extract `json:"extract,omitempty"`
+
+	// Transform is a jq program that is applied to the looked up data,
+	// after any extract, to produce the data that is stored. Programs that
+	// produce several outputs store them as an array.
+	// +optional
+	Transform *string `json:"transform,omitempty"`
+
+	// ConnectionDetails publishes fields of the stored data, after any
+	// extract or transform, as connection details. They replace the data of
+	// the connection secret, so details that are no longer published are
+	// removed. Use a secret source to publish sensitive fields without
+	// storing them.
+	// +optional
+	ConnectionDetails []ConnectionDetail `json:"connectionDetails,omitempty"`
+
+	// Staleness keeps the last data that was successfully looked up when the
+	// source is unavailable, rather than reporting an error.
+	// +optional
+	Staleness *StalenessPolicy `json:"staleness,omitempty"`
+}
+
+// A Variable is a value read from the data of another DataSource.
+type Variable struct {
+	// Name of the variable, as used in templates.
+	Name string `json:"name"`
+
+	// FieldPath of the value within the data of the referenced DataSource,
+	// e.g. 'region' or 'endpoints[0].url'. Values that are not strings are
+	// read as JSON.
+	FieldPath string `json:"fieldPath"`
+
+	// DataSourceRef references the DataSource to read the value from.
+	// +optional
+	DataSourceRef *xpv1.Reference `json:"dataSourceRef,omitempty"`
+
+	// DataSourceSelector selects the DataSource to read the value from.
+	// +optional
+	DataSourceSelector *xpv1.Selector `json:"dataSourceSelector,omitempty"`
+
+	// Value of the variable, resolved from the referenced DataSource. It is
+	// resolved again on every reconcile, so that it tracks changes to the
+	// referenced DataSource's data.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// MergeStrategy is how the objects of several sources are merged.
+// +kubebuilder:validation:Enum=deep;shallow
+type MergeStrategy string
+
+// Supported merge strategies.
+const (
+	// MergeDeep merges objects recursively.
+	MergeDeep MergeStrategy = "deep"
+
+	// MergeShallow merges only the top-level keys of objects.
+	MergeShallow MergeStrategy = "shallow"
+)
+
+// ArrayMerge is how the arrays of several sources are merged.
+// +kubebuilder:validation:Enum=replace;append
+type ArrayMerge string
+
+// Supported array merges.
+const (
+	// ArrayMergeReplace replaces arrays with those of later sources.
+	ArrayMergeReplace ArrayMerge = "replace"
+
+	// ArrayMergeAppend appends the arrays of later sources to those of
+	// earlier sources.
+	ArrayMergeAppend ArrayMerge = "append"
+)
+
+// MergeOptions configure how the data of several sources is merged into one
+// document. Sources are merged in order, so the values of later sources take
+// precedence over those of earlier sources. Values that are neither objects
+// nor arrays are always replaced.
+type MergeOptions struct {
+	// Strategy used to merge objects. Defaults to deep.
+	// +optional
+	// +kubebuilder:default=deep
+	Strategy MergeStrategy `json:"strategy,omitempty"`
+
+	// Arrays configures how arrays are merged. Defaults to replace.
+	// +optional
+	// +kubebuilder:default=replace
+	Arrays ArrayMerge `json:"arrays,omitempty"`
+}
+
+// StaleExpiry is what happens to stale data once it is too old to be kept.
+// +kubebuilder:validation:Enum=Fail;Clear
+type StaleExpiry string
+
+// Supported stale data expiries.
+const (
+	// StaleExpiryFail keeps the stale data but reports the lookup error.
+	StaleExpiryFail StaleExpiry = "Fail"
+
+	// StaleExpiryClear removes the stale data and reports the lookup
+	// error.
+	StaleExpiryClear StaleExpiry = "Clear"
+)
+
+// A StalenessPolicy keeps the last data that was successfully looked up when
+// its source is unavailable. Such data is marked with a Stale condition.
+type StalenessPolicy struct {
+	// MaxStaleness is how long after the last successful refresh stale data
+	// is kept for. Stale data is kept indefinitely if it is not specified.
+	// +optional
+	MaxStaleness *metav1.Duration `json:"maxStaleness,omitempty"`
+
+	// OnExpiry configures what happens to stale data once it is older than
+	// the max staleness. Defaults to Fail.
+	// +optional
+	// +kubebuilder:default=Fail
+	OnExpiry StaleExpiry `json:"onExpiry,omitempty"`
+}
+
+// SinkKind is the kind of object looked up data is written to.
+// +kubebuilder:validation:Enum=ConfigMap;Secret
+type SinkKind string
+
+// Supported sink kinds.
+const (
+	// SinkKindConfigMap writes data to a ConfigMap.
+	SinkKindConfigMap SinkKind = "ConfigMap"
+
+	// SinkKindSecret writes data to a Secret.
+	SinkKindSecret SinkKind = "Secret"
+)
+
+// Flatten is how looked up data is flattened into the keys of a ConfigMap or
+// Secret.
+// +kubebuilder:validation:Enum=json;keys;paths
+type Flatten string
+
+// Supported flattening strategies.
+const (
+	// FlattenJSON writes the whole document as JSON to a single key.
+	FlattenJSON Flatten = "json"
+
+	// FlattenKeys writes each top-level key of the document to a key of
+	// the same name.
+	FlattenKeys Flatten = "keys"
+
+	// FlattenPaths writes each value within the document to a key named
+	// after its path, such as 'db.hosts.0'.
+	FlattenPaths Flatten = "paths"
+)
+
+// WriteTo configures a ConfigMap or Secret that looked up data is written to.
+// It is created in the namespace of the ProviderConfig, and is controlled by
+// the DataSource so that it is deleted along with it. Values that are
+// strings are written as-is, while all other values are written as JSON.
+type WriteTo struct {
+	// Kind of object to write to.
+	// +optional
+	// +kubebuilder:default=ConfigMap
+	Kind SinkKind `json:"kind,omitempty"`
+
+	// Name of the object to write to.
+	Name string `json:"name"`
+
+	// Flatten configures how data is flattened into keys. Defaults to json.
+	// +optional
+	Flatten *Flatten `json:"flatten,omitempty"`
+
+	// Key the document is written to when flattened as json. Defaults to
+	// 'data.json'.
+	// +optional
+	Key *string `json:"key,omitempty"`
+
+	// Separator of the elements of paths when flattened as paths. Defaults
+	// to '.'.
+	// +optional
+	Separator *string `json:"separator,omitempty"`
 }
 
 // A DataSourceSpec defines the desired state of a DataSource.
 type DataSourceSpec struct {
 	xpv1.ResourceSpec `json:",inline"`
 	ForProvider       DataSourceParameters `json:"forProvider"`
+
+	// WriteTo writes the looked up data to a ConfigMap or Secret whenever
+	// it is refreshed.
+	// +optional
+	WriteTo *WriteTo `json:"writeTo,omitempty"`
 }
 
 // A DataSourceStatus represents the observed state of a DataSource.
@@ -65,6 +663,68 @@ type DataSourceStatus struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +optional
 	AtProvider *runtime.RawExtension `json:"atProvider,omitempty"`
+
+	// LastRefreshTime is the last time the data in AtProvider was
+	// refreshed from its source, or was kept because its source was
+	// unavailable.
+	// +optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
+
+	// LastSuccessfulRefresh is the last time the data in AtProvider was
+	// successfully looked up from its source.
+	// +optional
+	LastSuccessfulRefresh *metav1.Time `json:"lastSuccessfulRefresh,omitempty"`
+
+	// NextRefreshTime is the time the data in AtProvider will next be
+	// refreshed from its source, if a refresh interval or schedule is
+	// configured.
+	// +optional
+	NextRefreshTime *metav1.Time `json:"nextRefreshTime,omitempty"`
+
+	// ObservedGeneration is the generation of this DataSource that the
+	// data in AtProvider was retrieved for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ETag is the entity tag of the URL response the data in AtProvider
+	// was retrieved from, if any.
+	// +optional
+	ETag string `json:"etag,omitempty"`
+
+	// LastModified is the last modification time of the URL response the
+	// data in AtProvider was retrieved from, if any.
+	// +optional
+	LastModified string `json:"lastModified,omitempty"`
+
+	// Source is the source the data in AtProvider was retrieved from.
+	// +optional
+	Source *SourceStatus `json:"source,omitempty"`
+
+	// SkippedSources are the sources that were tried before the source the
+	// data in AtProvider was retrieved from, and why they were skipped.
+	// +optional
+	SkippedSources []SourceStatus `json:"skippedSources,omitempty"`
+
+	// Provenance maps the paths of the keys of merged data to the index of
+	// the source their value was merged from, e.g. 'db.host: 1'. Values
+	// whose own path is not recorded came from the source of their nearest
+	// recorded parent.
+	// +optional
+	Provenance map[string]int `json:"provenance,omitempty"`
+}
+
+// A SourceStatus describes a source of a DataSource that was looked up.
+type SourceStatus struct {
+	// Index of the source within sources, or 0 if no sources are
+	// specified.
+	Index int `json:"index"`
+
+	// Type of the source.
+	Type SourceType `json:"type"`
+
+	// Reason the source was skipped, if it was.
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -28,6 +28,176 @@ type ProviderConfigSpec struct {
 	// external data sources that exist on-cluster.
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+
+	// Credentials used to authenticate requests made by URL sources.
+	// +optional
+	Credentials *ProviderCredentials `json:"credentials,omitempty"`
+
+	// TLS configures the TLS connections made by URL sources.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// URLPolicy restricts the URLs that URL sources may request.
+	// +optional
+	URLPolicy *URLPolicy `json:"urlPolicy,omitempty"`
+
+	// ObjectNamespaces from which kubernetes sources may read objects, in
+	// addition to the above Namespace. Objects in other namespaces cannot
+	// be read.
+	// +optional
+	ObjectNamespaces []string `json:"objectNamespaces,omitempty"`
+}
+
+// A URLPolicy restricts the URLs that URL sources may request. Addresses are
+// checked when a connection is made, after hostnames have been resolved, so
+// that DNS cannot be used to bypass the policy. Proxies configured by the
+// environment are not used when a policy is specified.
+type URLPolicy struct {
+	// AllowedSchemes that URLs may use. Any scheme is allowed if none are
+	// specified.
+	// +optional
+	AllowedSchemes []string `json:"allowedSchemes,omitempty"`
+
+	// AllowedHosts that may be requested. A leading wildcard, e.g.
+	// *.example.org, matches any subdomain. Any host is allowed if none are
+	// specified.
+	// +optional
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+
+	// DeniedHosts that may not be requested, even if they are allowed.
+	// Leading wildcards are supported as for allowedHosts.
+	// +optional
+	DeniedHosts []string `json:"deniedHosts,omitempty"`
+
+	// AllowedCIDRs that may be connected to. Any address that is not
+	// otherwise denied is allowed if none are specified. Addresses in these
+	// ranges are allowed even if denyPrivateNetworks is true.
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// DeniedCIDRs that may not be connected to, even if they are allowed.
+	// +optional
+	DeniedCIDRs []string `json:"deniedCIDRs,omitempty"`
+
+	// DenyPrivateNetworks denies connections to loopback, link-local,
+	// private, shared and unspecified addresses, such as in-cluster
+	// services and cloud metadata endpoints.
+	// +optional
+	DenyPrivateNetworks bool `json:"denyPrivateNetworks,omitempty"`
+}
+
+// TLSConfig configures the TLS connections made by URL sources.
+type TLSConfig struct {
+	// CABundle selects PEM encoded CA certificates that are trusted in
+	// addition to the system's CA certificates.
+	// +optional
+	CABundle *KeySource `json:"caBundle,omitempty"`
+
+	// ClientCertificate selects a PEM encoded client certificate that is
+	// presented to servers. A clientKeySecretRef must also be specified.
+	// +optional
+	ClientCertificate *KeySource `json:"clientCertificate,omitempty"`
+
+	// ClientKeySecretRef references a Secret key containing the PEM encoded
+	// private key of the client certificate.
+	// +optional
+	ClientKeySecretRef *xpv1.SecretKeySelector `json:"clientKeySecretRef,omitempty"`
+
+	// SPKIPins are base64 encoded SHA-256 hashes of public keys. If any are
+	// specified, servers must present a certificate with one of these
+	// public keys.
+	// +optional
+	SPKIPins []string `json:"spkiPins,omitempty"`
+}
+
+// A KeySource selects a key of a ConfigMap or a Secret.
+type KeySource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap.
+	// +optional
+	ConfigMapKeyRef *ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of a Secret.
+	// +optional
+	SecretKeyRef *xpv1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// A ConfigMapKeySelector is a reference to a ConfigMap key in an arbitrary
+// namespace.
+type ConfigMapKeySelector struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap.
+	Namespace string `json:"namespace"`
+
+	// Key whose value will be used.
+	Key string `json:"key"`
+}
+
+// AuthType is the type of authentication applied to requests.
+type AuthType string
+
+// Supported authentication types.
+const (
+	// AuthTypeBasic uses HTTP basic authentication. The credentials must
+	// be of the form username:password.
+	AuthTypeBasic AuthType = "Basic"
+
+	// AuthTypeBearer sends the credentials as a bearer token.
+	AuthTypeBearer AuthType = "Bearer"
+
+	// AuthTypeAPIKey sends the credentials in an API key header.
+	AuthTypeAPIKey AuthType = "APIKey"
+
+	// AuthTypeOAuth2 uses the credentials as the client secret of an
+	// OAuth2 client credentials flow, and sends the resulting access token
+	// as a bearer token.
+	AuthTypeOAuth2 AuthType = "OAuth2"
+)
+
+// ProviderCredentials required to authenticate requests made by URL sources.
+type ProviderCredentials struct {
+	// Source of the provider credentials.
+	// +kubebuilder:validation:Enum=None;Secret;Environment;Filesystem
+	Source xpv1.CredentialsSource `json:"source"`
+
+	// Type of authentication the credentials are used for.
+	// +kubebuilder:validation:Enum=Basic;Bearer;APIKey;OAuth2
+	// +kubebuilder:default=Bearer
+	// +optional
+	Type AuthType `json:"type,omitempty"`
+
+	// APIKeyHeader is the header the credentials are sent in when the type
+	// is APIKey. Defaults to X-API-Key.
+	// +optional
+	APIKeyHeader *string `json:"apiKeyHeader,omitempty"`
+
+	// OAuth2 configures the client credentials flow used when the type is
+	// OAuth2.
+	// +optional
+	OAuth2 *OAuth2ClientCredentials `json:"oauth2,omitempty"`
+
+	xpv1.CommonCredentialSelectors `json:",inline"`
+}
+
+// OAuth2ClientCredentials configures an OAuth2 client credentials flow. The
+// client secret is read from the ProviderConfig's credentials.
+type OAuth2ClientCredentials struct {
+	// TokenURL is the URL of the authorization server's token endpoint.
+	TokenURL string `json:"tokenURL"`
+
+	// ClientID of the OAuth2 client.
+	// +optional
+	ClientID *string `json:"clientID,omitempty"`
+
+	// ClientIDSecretRef references a Secret key containing the client ID of
+	// the OAuth2 client. It is used if clientID is not set.
+	// +optional
+	ClientIDSecretRef *xpv1.SecretKeySelector `json:"clientIDSecretRef,omitempty"`
+
+	// Scopes to request.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
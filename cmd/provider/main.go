@@ -29,6 +29,7 @@ import (
 
 	"github.com/benagricola/provider-externaldata/apis"
 	"github.com/benagricola/provider-externaldata/internal/controller"
+	"github.com/benagricola/provider-externaldata/internal/controller/datasource"
 )
 
 func main() {
@@ -36,9 +37,17 @@ func main() {
 		app            = kingpin.New(filepath.Base(os.Args[0]), "ExternalData support for Crossplane.").DefaultEnvars()
 		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
 		syncPeriod     = app.Flag("sync", "Controller manager sync period such as 300ms, 1.5h, or 2h45m").Short('s').Default("1h").Duration()
+		cacheTTL       = app.Flag("cache-ttl", "How long responses from URL data sources are cached and shared between DataSources. Set to 0 to disable caching.").Default("30s").Duration()
+		tfTimeout      = app.Flag("transform-timeout", "How long the transform of a DataSource may be evaluated for.").Default("1s").Duration()
+		tfMemoryLimit  = app.Flag("transform-memory-limit", "How much memory the transform of a DataSource may use, such as 64MiB. Transforms are evaluated in their own process unless this is set to 0, which disables the limit.").Default("64MiB").Bytes()
 		leaderElection = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
+
+		_         = app.Command("start", "Start the provider.").Default()
+		transform = app.Command(datasource.TransformCommand, "Evaluate a transform read from stdin.").Hidden()
 	)
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	if kingpin.MustParse(app.Parse(os.Args[1:])) == transform.FullCommand() {
+		os.Exit(datasource.RunTransform(os.Stdin, os.Stdout, os.Stderr))
+	}
 
 	zl := zap.New(zap.UseDevMode(*debug))
 	log := logging.NewLogrLogger(zl.WithName("provider-externaldata"))
@@ -49,7 +58,7 @@ func main() {
 		ctrl.SetLogger(zl)
 	}
 
-	log.Debug("Starting", "sync-period", syncPeriod.String())
+	log.Debug("Starting", "sync-period", syncPeriod.String(), "cache-ttl", cacheTTL.String(), "transform-timeout", tfTimeout.String(), "transform-memory-limit", tfMemoryLimit.String())
 
 	cfg, err := ctrl.GetConfig()
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
@@ -63,6 +72,10 @@ func main() {
 
 	rl := ratelimiter.NewDefaultProviderRateLimiter(ratelimiter.DefaultProviderRPS)
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add ExternalData APIs to scheme")
-	kingpin.FatalIfError(controller.Setup(mgr, log, rl), "Cannot setup ExternalData controllers")
+	kingpin.FatalIfError(controller.Setup(mgr, log, rl, datasource.Options{
+		CacheTTL:             *cacheTTL,
+		TransformTimeout:     *tfTimeout,
+		TransformMemoryLimit: uint64(*tfMemoryLimit),
+	}), "Cannot setup ExternalData controllers")
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
@@ -3,15 +3,29 @@ module github.com/benagricola/provider-externaldata
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/andybalholm/cascadia v1.2.0
+	github.com/antchfx/htmlquery v1.2.3
 	github.com/crossplane/crossplane-runtime v0.13.0
 	github.com/crossplane/crossplane-tools v0.0.0-20201201125637-9ddc70edfd0d
 	github.com/go-resty/resty/v2 v2.6.0
-	github.com/google/go-cmp v0.5.2
+	github.com/google/go-cmp v0.5.4
+	github.com/itchyny/gojq v0.12.7
+	github.com/jmespath/go-jmespath v0.4.0
+	github.com/joho/godotenv v1.4.0
+	github.com/magiconair/properties v1.8.1
 	github.com/pkg/errors v0.9.1
+	github.com/robfig/cron/v3 v3.0.1
+	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4
+	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	golang.org/x/sync v0.1.0
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+	gopkg.in/ini.v1 v1.51.0
 	k8s.io/api v0.20.1
 	k8s.io/apimachinery v0.20.1
 	k8s.io/client-go v0.20.1
+	k8s.io/utils v0.0.0-20201110183641-67b214c5f920
 	sigs.k8s.io/controller-runtime v0.8.0
 	sigs.k8s.io/controller-tools v0.3.0
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+const (
+	errGetCreds         = "cannot get credentials"
+	errBasicCreds       = "basic credentials must be of the form username:password"
+	errFmtUnknownAuth   = "unknown authentication type %s"
+	defaultAPIKeyHeader = "X-API-Key"
+)
+
+// An authenticator configures requests made by URL sources to be
+// authenticated.
+type authenticator interface {
+	authenticate(r *request)
+}
+
+// credentials are applied to requests made by URL sources as a header.
+type credentials struct {
+	header string
+	value  string
+}
+
+// authenticate sets the credentials on the supplied request. Credentials take
+// precedence over any header of the same name configured by the DataSource.
+func (c *credentials) authenticate(r *request) {
+	r.Headers[c.header] = c.value
+}
+
+// getAuthenticator returns the authenticator configured by the supplied
+// ProviderConfig, or nil if it configures no credentials. OAuth2 token
+// sources are shared between calls using the supplied tokenCache.
+func getAuthenticator(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig, tokens *tokenCache) (authenticator, error) {
+	pcc := pc.Spec.Credentials
+	if pcc == nil || pcc.Source == xpv1.CredentialsSourceNone {
+		return nil, nil
+	}
+
+	data, err := resource.CommonCredentialExtractor(ctx, pcc.Source, kube, pcc.CommonCredentialSelectors)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	v := strings.TrimSpace(string(data))
+
+	switch pcc.Type {
+	case apisv1alpha1.AuthTypeBasic:
+		if !strings.Contains(v, ":") {
+			return nil, errors.New(errBasicCreds)
+		}
+		return &credentials{header: "Authorization", value: "Basic " + base64.StdEncoding.EncodeToString([]byte(v))}, nil
+	case apisv1alpha1.AuthTypeBearer, "":
+		return &credentials{header: "Authorization", value: "Bearer " + v}, nil
+	case apisv1alpha1.AuthTypeAPIKey:
+		h := defaultAPIKeyHeader
+		if pcc.APIKeyHeader != nil {
+			h = *pcc.APIKeyHeader
+		}
+		return &credentials{header: http.CanonicalHeaderKey(h), value: v}, nil
+	case apisv1alpha1.AuthTypeOAuth2:
+		ts, err := getTokenSource(ctx, kube, pc, v, tokens)
+		if err != nil {
+			return nil, err
+		}
+		return ts, nil
+	default:
+		return nil, errors.Errorf(errFmtUnknownAuth, pcc.Type)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+func TestGetAuthenticator(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	secret := func(v string) test.MockGetFn {
+		return test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.(*apiv1.Secret).Data = map[string][]byte{"creds": []byte(v)}
+			return nil
+		})
+	}
+
+	pc := func(t apisv1alpha1.AuthType, h *string) *apisv1alpha1.ProviderConfig {
+		return &apisv1alpha1.ProviderConfig{Spec: apisv1alpha1.ProviderConfigSpec{
+			Credentials: &apisv1alpha1.ProviderCredentials{
+				Source:       xpv1.CredentialsSourceSecret,
+				Type:         t,
+				APIKeyHeader: h,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{
+						SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "creds"},
+						Key:             "creds",
+					},
+				},
+			},
+		}}
+	}
+
+	type args struct {
+		kube client.Client
+		pc   *apisv1alpha1.ProviderConfig
+	}
+
+	type want struct {
+		a   authenticator
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoCredentials": {
+			reason: "No credentials should be returned if the ProviderConfig configures none.",
+			args: args{
+				pc: &apisv1alpha1.ProviderConfig{},
+			},
+		},
+		"GetError": {
+			reason: "Errors getting the credentials should be returned.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				pc:   pc(apisv1alpha1.AuthTypeBearer, nil),
+			},
+			want: want{
+				err: errors.Wrap(errors.Wrap(errBoom, "cannot get credentials secret"), errGetCreds),
+			},
+		},
+		"Bearer": {
+			reason: "Bearer credentials should be sent as a bearer token.",
+			args: args{
+				kube: &test.MockClient{MockGet: secret("token\n")},
+				pc:   pc("", nil),
+			},
+			want: want{
+				a: &credentials{header: "Authorization", value: "Bearer token"},
+			},
+		},
+		"Basic": {
+			reason: "Basic credentials should be sent base64 encoded.",
+			args: args{
+				kube: &test.MockClient{MockGet: secret("user:pass")},
+				pc:   pc(apisv1alpha1.AuthTypeBasic, nil),
+			},
+			want: want{
+				a: &credentials{header: "Authorization", value: "Basic dXNlcjpwYXNz"},
+			},
+		},
+		"BasicInvalid": {
+			reason: "Basic credentials without a password should be rejected.",
+			args: args{
+				kube: &test.MockClient{MockGet: secret("user")},
+				pc:   pc(apisv1alpha1.AuthTypeBasic, nil),
+			},
+			want: want{
+				err: errors.New(errBasicCreds),
+			},
+		},
+		"APIKey": {
+			reason: "API keys should be sent in the configured header.",
+			args: args{
+				kube: &test.MockClient{MockGet: secret("key")},
+				pc:   pc(apisv1alpha1.AuthTypeAPIKey, pointer.StringPtr("x-api-token")),
+			},
+			want: want{
+				a: &credentials{header: "X-Api-Token", value: "key"},
+			},
+		},
+		"OAuth2Missing": {
+			reason: "OAuth2 credentials should require an OAuth2 configuration.",
+			args: args{
+				kube: &test.MockClient{MockGet: secret("client-secret")},
+				pc:   pc(apisv1alpha1.AuthTypeOAuth2, nil),
+			},
+			want: want{
+				err: errors.New(errOAuth2),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, err := getAuthenticator(context.Background(), tc.args.kube, tc.args.pc, newTokenCache())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ngetAuthenticator(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.a, a, cmp.AllowUnexported(credentials{})); diff != "" {
+				t.Errorf("\n%s\ngetAuthenticator(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
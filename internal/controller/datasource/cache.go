@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+// A request is the effective HTTP request made by a URL source. Requests
+// that are identical, including any credentials applied to them, share cached
+// responses.
+type request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Query   map[string]string `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+
+	// Auth identifies the credentials the request is authenticated with
+	// when they are not sent as one of its headers.
+	Auth string `json:"auth,omitempty"`
+
+	// Client identifies the TLS configuration of the client the request is
+	// made with.
+	Client string `json:"client,omitempty"`
+
+	// tokens authenticate the request using OAuth2 access tokens.
+	tokens *tokenSource
+
+	// client the request is made with.
+	client *resty.Client
+}
+
+// key returns a string uniquely identifying the request.
+func (r request) key() string {
+	// Marshalling a struct of strings and maps cannot fail, and map keys are
+	// sorted, so identical requests always produce identical keys.
+	b, _ := json.Marshal(r)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// staleRetention is how long expired responses are retained after they
+// expire, so that they may be revalidated rather than retrieved again.
+const staleRetention = 10 * time.Minute
+
+// A response is the result of a request made by a URL source.
+type response struct {
+	body        []byte
+	contentType string
+	validators  validators
+
+	// notModified is true if the response was to a conditional request
+	// and the requested resource has not been modified. Such responses
+	// have no body.
+	notModified bool
+}
+
+type cacheEntry struct {
+	res     *response
+	expires time.Time
+}
+
+// A responseCache shares responses between all DataSources making the same
+// request. Concurrent fetches of the same request are coalesced into a single
+// request, and successful responses are cached for the configured TTL.
+type responseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	group   singleflight.Group
+}
+
+// newResponseCache returns a responseCache that caches responses for the
+// supplied TTL. Responses are never cached if the TTL is zero, but concurrent
+// requests are still coalesced.
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// Fetch returns the cached response to the supplied request, or calls fetch
+// to retrieve it if it is not cached or has expired. Expired responses are
+// revalidated by passing their validators to fetch. The supplied validators
+// are passed instead when there is no cached response to revalidate, in which
+// case a not modified response is returned as-is.
+func (c *responseCache) Fetch(r request, v validators, fetch func(validators) (*response, error)) (*response, error) {
+	if c == nil {
+		return fetch(v)
+	}
+
+	k := r.key()
+	if e, ok := c.entry(k); ok && c.now().Before(e.expires) {
+		return e.res, nil
+	}
+
+	res, err, _ := c.group.Do(k, func() (interface{}, error) {
+		// The response may have been stored by a fetch that completed
+		// after we first checked, but before we joined the group.
+		e, ok := c.entry(k)
+		if ok && c.now().Before(e.expires) {
+			return e.res, nil
+		}
+
+		prev := v
+		if ok && !e.res.validators.empty() {
+			prev = e.res.validators
+		}
+		res, err := fetch(prev)
+		if err != nil {
+			return nil, err
+		}
+		if res.notModified {
+			if !ok || !prev.matches(e.res.validators) {
+				return res, nil
+			}
+			res = e.res
+		}
+		c.store(k, res)
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*response), nil
+}
+
+// entry returns the cached entry for the supplied key, if any.
+func (c *responseCache) entry(k string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	return e, ok
+}
+
+// store caches the supplied response, and evicts any responses that expired
+// longer ago than they are retained for.
+func (c *responseCache) store(k string, res *response) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for ek, e := range c.entries {
+		if now.After(e.expires.Add(staleRetention)) {
+			delete(c.entries, ek)
+		}
+	}
+	c.entries[k] = cacheEntry{res: res, expires: now.Add(c.ttl)}
+}
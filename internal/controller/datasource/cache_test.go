@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestResponseCacheFetch(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Now()
+	r := request{URL: "https://example.org"}
+
+	type want struct {
+		body        []byte
+		notModified bool
+		err         error
+		fetches     int
+	}
+
+	cases := map[string]struct {
+		reason      string
+		ttl         time.Duration
+		after       time.Duration
+		v           validators
+		err         error
+		notModified bool
+		want        want
+	}{
+		"Cached": {
+			reason: "A second fetch within the TTL should be served from the cache.",
+			ttl:    time.Minute,
+			after:  time.Second,
+			want:   want{body: []byte("1"), fetches: 1},
+		},
+		"Expired": {
+			reason: "A second fetch after the TTL should make a new request.",
+			ttl:    time.Minute,
+			after:  2 * time.Minute,
+			want:   want{body: []byte("2"), fetches: 2},
+		},
+		"Revalidated": {
+			reason:      "An expired response that is not modified should continue to be served.",
+			ttl:         time.Minute,
+			after:       2 * time.Minute,
+			notModified: true,
+			want:        want{body: []byte("1"), fetches: 2},
+		},
+		"Conditional": {
+			reason:      "The supplied validators should be used when there is no cached response to revalidate, and a not modified response returned as-is.",
+			after:       time.Second,
+			v:           validators{etag: "1"},
+			notModified: true,
+			want:        want{notModified: true, fetches: 2},
+		},
+		"Disabled": {
+			reason: "Responses should never be cached when the TTL is zero.",
+			after:  time.Second,
+			want:   want{body: []byte("2"), fetches: 2},
+		},
+		"Error": {
+			reason: "Failed requests should not be cached.",
+			ttl:    time.Minute,
+			after:  time.Second,
+			err:    errBoom,
+			want:   want{err: errBoom, fetches: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newResponseCache(tc.ttl)
+			c.now = func() time.Time { return now }
+
+			fetches := 0
+			fetch := func(v validators) (*response, error) {
+				fetches++
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				if tc.notModified && v.matches(validators{etag: "1"}) {
+					return &response{notModified: true, validators: v}, nil
+				}
+				b := []byte{byte('0' + fetches)}
+				return &response{body: b, validators: validators{etag: string(b)}}, nil
+			}
+
+			_, _ = c.Fetch(r, validators{}, fetch)
+			c.now = func() time.Time { return now.Add(tc.after) }
+			res, err := c.Fetch(r, tc.v, fetch)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Fetch(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			var body []byte
+			notModified := false
+			if res != nil {
+				body, notModified = res.body, res.notModified
+			}
+			if diff := cmp.Diff(tc.want.body, body); diff != "" {
+				t.Errorf("\n%s\nc.Fetch(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.notModified, notModified); diff != "" {
+				t.Errorf("\n%s\nc.Fetch(...): -want not modified, +got not modified:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.fetches, fetches); diff != "" {
+				t.Errorf("\n%s\nc.Fetch(...): -want fetches, +got fetches:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResponseCacheCoalesce(t *testing.T) {
+	c := newResponseCache(time.Minute)
+	r := request{URL: "https://example.org"}
+
+	mu := sync.Mutex{}
+	fetches := 0
+	release := make(chan struct{})
+	fetch := func(_ validators) (*response, error) {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		<-release
+		return &response{body: []byte("{}")}, nil
+	}
+
+	// Every goroutine has started before the fetch is released. Goroutines
+	// that join the group after the fetch completes are served the response
+	// it cached, so only one fetch is made regardless of scheduling.
+	started := sync.WaitGroup{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		started.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			_, _ = c.Fetch(r, validators{}, fetch)
+		}()
+	}
+
+	started.Wait()
+	close(release)
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("c.Fetch(...): want 1 fetch for concurrent identical requests, got %d", fetches)
+	}
+}
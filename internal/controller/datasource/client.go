@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+// An httpClient makes the requests of URL sources.
+type httpClient struct {
+	// id identifies the configuration of the client, without revealing its
+	// client key.
+	id     string
+	client *resty.Client
+}
+
+// configure configures the supplied request to be made using this client.
+func (c *httpClient) configure(r *request) {
+	r.Client = c.id
+	r.client = c.client
+}
+
+// newRestyClient returns a client for URL sources that uses the supplied TLS
+// configuration and enforces the supplied policy. The default TLS
+// configuration is used and no policy is enforced if they are nil.
+func newRestyClient(tc *tls.Config, p *urlPolicy) *resty.Client {
+	d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tc
+
+	var rt http.RoundTripper = t
+	if p != nil {
+		d.Control = p.control
+		t.Proxy = nil
+		rt = &policyTransport{policy: p, transport: t}
+	}
+	t.DialContext = d.DialContext
+
+	// Clients are shared by every DataSource of a ProviderConfig, and
+	// cookies are not part of the key of cached responses, so cookies set
+	// by the response to one request must not be sent with the next.
+	c := resty.New()
+	c.SetCookieJar(nil)
+	c.SetTransport(rt)
+	c.SetRetryCount(1)
+	c.SetTimeout(1 * time.Second)
+	return c
+}
+
+// clientConfig is the configuration of a ProviderConfig's httpClient.
+type clientConfig struct {
+	CABundle   string                  `json:"caBundle,omitempty"`
+	ClientCert string                  `json:"clientCert,omitempty"`
+	ClientKey  string                  `json:"clientKey,omitempty"`
+	SPKIPins   []string                `json:"spkiPins,omitempty"`
+	URLPolicy  *apisv1alpha1.URLPolicy `json:"urlPolicy,omitempty"`
+}
+
+// A clientCache stores an httpClient for each ProviderConfig, so that
+// connections are reused across reconciles.
+type clientCache struct {
+	mu      sync.Mutex
+	clients map[string]*httpClient
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{clients: map[string]*httpClient{}}
+}
+
+// Get returns the httpClient of the named ProviderConfig, replacing it if the
+// supplied configuration differs from the configuration it was created with.
+func (c *clientCache) Get(name string, cc clientConfig) (*httpClient, error) {
+	// Marshalling a struct of strings cannot fail.
+	b, _ := json.Marshal(cc)
+	h := sha256.Sum256(b)
+	id := hex.EncodeToString(h[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if hc, ok := c.clients[name]; ok && hc.id == id {
+		return hc, nil
+	}
+
+	tc, err := tlsConfig(cc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newURLPolicy(cc.URLPolicy)
+	if err != nil {
+		return nil, err
+	}
+	hc := &httpClient{id: id, client: newRestyClient(tc, p)}
+	c.clients[name] = hc
+	return hc, nil
+}
+
+// getHTTPClient returns the httpClient configured by the supplied
+// ProviderConfig. Clients are shared between calls using the supplied
+// clientCache.
+func getHTTPClient(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig, clients *clientCache) (*httpClient, error) {
+	cc := clientConfig{URLPolicy: pc.Spec.URLPolicy}
+	if t := pc.Spec.TLS; t != nil {
+		var err error
+		if cc.CABundle, err = resolveKey(ctx, kube, t.CABundle); err != nil {
+			return nil, errors.Wrap(err, errGetCABundle)
+		}
+		if cc.ClientCert, err = resolveKey(ctx, kube, t.ClientCertificate); err != nil {
+			return nil, errors.Wrap(err, errGetClientCert)
+		}
+		if t.ClientKeySecretRef != nil {
+			if cc.ClientKey, err = resolveKey(ctx, kube, &apisv1alpha1.KeySource{SecretKeyRef: t.ClientKeySecretRef}); err != nil {
+				return nil, errors.Wrap(err, errGetClientKey)
+			}
+		}
+		cc.SPKIPins = t.SPKIPins
+	}
+	return clients.Get(pc.GetName(), cc)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+// coerce converts the supplied string into a typed value, as configured by
+// the supplied coercion. Strings that cannot be converted are returned as-is.
+func coerce(c v1alpha1.Coercion, s string) interface{} {
+	switch c {
+	case v1alpha1.CoercionScalars:
+		return coerceScalar(s)
+	case v1alpha1.CoercionDocuments:
+		if v, ok := coerceDocument(s); ok {
+			return v
+		}
+		return coerceScalar(s)
+	}
+	return s
+}
+
+// coerceScalar converts strings that are JSON numbers, booleans or null. Other
+// strings, including numbers with leading zeros such as "0123" and numbers too
+// large to be represented as a float64 such as "1e400", are returned as-is.
+func coerceScalar(s string) interface{} {
+	switch s {
+	case "true", "false", "null":
+		return json.RawMessage(s)
+	}
+	if s == "" || (s[0] != '-' && (s[0] < '0' || s[0] > '9')) {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal([]byte(s), &n); err != nil {
+		return s
+	}
+	// Consumers of the data would otherwise read such numbers as infinity,
+	// or fail to read them at all.
+	if _, err := n.Float64(); err != nil {
+		return s
+	}
+	return n
+}
+
+// coerceDocument parses strings that are JSON objects or arrays, or YAML
+// documents of several lines that are mappings or sequences.
+func coerceDocument(s string) (interface{}, bool) {
+	t := strings.TrimSpace(s)
+	if strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[") {
+		var v interface{}
+		if err := json.Unmarshal([]byte(t), &v); err == nil {
+			return v, true
+		}
+	}
+	if !strings.Contains(t, "\n") {
+		return nil, false
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(t), &v); err != nil {
+		return nil, false
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return v, true
+	}
+	return nil, false
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestCoerce(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		c      v1alpha1.Coercion
+		s      string
+		want   string
+	}{
+		"None": {
+			reason: "Values should not be converted without coercion.",
+			c:      v1alpha1.CoercionNone,
+			s:      "3",
+			want:   `"3"`,
+		},
+		"Integer": {
+			reason: "Integers should be converted into numbers.",
+			c:      v1alpha1.CoercionScalars,
+			s:      "3",
+			want:   `3`,
+		},
+		"Float": {
+			reason: "Floats should be converted into numbers without losing precision.",
+			c:      v1alpha1.CoercionScalars,
+			s:      "-0.10000000000000000001",
+			want:   `-0.10000000000000000001`,
+		},
+		"OutOfRange": {
+			reason: "Numbers too large to be represented as a float64 should not be converted.",
+			c:      v1alpha1.CoercionScalars,
+			s:      "-1e400",
+			want:   `"-1e400"`,
+		},
+		"LeadingZero": {
+			reason: "Numbers with leading zeros should not be converted.",
+			c:      v1alpha1.CoercionScalars,
+			s:      "02134",
+			want:   `"02134"`,
+		},
+		"Boolean": {
+			reason: "Booleans should be converted.",
+			c:      v1alpha1.CoercionScalars,
+			s:      "true",
+			want:   `true`,
+		},
+		"String": {
+			reason: "Other strings should not be converted.",
+			c:      v1alpha1.CoercionScalars,
+			s:      "True",
+			want:   `"True"`,
+		},
+		"ScalarsDocument": {
+			reason: "Documents should not be parsed when only scalars are coerced.",
+			c:      v1alpha1.CoercionScalars,
+			s:      `{"a":1}`,
+			want:   `"{\"a\":1}"`,
+		},
+		"JSONDocument": {
+			reason: "JSON documents should be parsed when documents are coerced.",
+			c:      v1alpha1.CoercionDocuments,
+			s:      `[{"a":1}]`,
+			want:   `[{"a":1}]`,
+		},
+		"YAMLDocument": {
+			reason: "Multi-line YAML documents should be parsed when documents are coerced.",
+			c:      v1alpha1.CoercionDocuments,
+			s:      "host: db.example.org\nport: 5432\n",
+			want:   `{"host":"db.example.org","port":5432}`,
+		},
+		"SingleLine": {
+			reason: "Single line strings that happen to be YAML should not be parsed.",
+			c:      v1alpha1.CoercionDocuments,
+			s:      "note: hello",
+			want:   `"note: hello"`,
+		},
+		"DocumentsScalar": {
+			reason: "Scalars should be converted when documents are coerced.",
+			c:      v1alpha1.CoercionDocuments,
+			s:      "42",
+			want:   `42`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, _ := json.Marshal(coerce(tc.c, tc.s))
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\ncoerce(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
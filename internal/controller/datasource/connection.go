@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errConnectionDetailsObject = "connection details can only be published from data that is an object"
+	errFmtConnectionDetail     = "cannot publish connection detail %s"
+	errPublishConnection       = "cannot create or update connection secret"
+)
+
+// connectionDetails returns the fields of the supplied data selected by the
+// supplied connection details. String fields are returned as-is, and all
+// other fields as JSON.
+func connectionDetails(cds []v1alpha1.ConnectionDetail, re *runtime.RawExtension) (managed.ConnectionDetails, error) {
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(re.Raw, &obj); err != nil {
+		return nil, errors.Wrap(err, errConnectionDetailsObject)
+	}
+	p := fieldpath.Pave(obj)
+
+	out := make(managed.ConnectionDetails, len(cds))
+	for _, cd := range cds {
+		v, err := p.GetValue(cd.FromFieldPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtConnectionDetail, cd.Name)
+		}
+		if s, ok := v.(string); ok {
+			out[cd.Name] = []byte(s)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtConnectionDetail, cd.Name)
+		}
+		out[cd.Name] = b
+	}
+	return out, nil
+}
+
+// connectionDetailsOf returns the connection details of the supplied
+// DataSource: the fields of its stored data selected by its connection
+// details, along with the supplied connection details of its sources.
+func connectionDetailsOf(cr *v1alpha1.DataSource, sd managed.ConnectionDetails) (managed.ConnectionDetails, error) {
+	cds := cr.Spec.ForProvider.ConnectionDetails
+	if len(cds) == 0 || cr.Status.AtProvider == nil {
+		return sd, nil
+	}
+	out, err := connectionDetails(cds, cr.Status.AtProvider)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range sd {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// A connectionPublisher publishes the connection details of a DataSource to
+// its connection secret. Unlike a managed.APISecretPublisher it replaces the
+// data of the secret, so that details the DataSource no longer has are
+// removed. Details are merged while the data of the DataSource is stale,
+// because the details of an unavailable Secret source cannot be looked up.
+type connectionPublisher struct {
+	replace resource.Applicator
+	merge   resource.Applicator
+	typer   runtime.ObjectTyper
+}
+
+func newConnectionPublisher(c client.Client, ot runtime.ObjectTyper) *connectionPublisher {
+	return &connectionPublisher{
+		replace: resource.NewAPIUpdatingApplicator(c),
+		merge:   resource.NewAPIPatchingApplicator(c),
+		typer:   ot,
+	}
+}
+
+// PublishConnection details of the supplied DataSource to its connection
+// secret.
+func (p *connectionPublisher) PublishConnection(ctx context.Context, mg resource.Managed, c managed.ConnectionDetails) error {
+	if mg.GetWriteConnectionSecretToReference() == nil {
+		return nil
+	}
+
+	s := resource.ConnectionSecretFor(mg, resource.MustGetKind(mg, p.typer))
+	s.Data = c
+	a := p.replace
+	if mg.GetCondition(v1alpha1.TypeStale).Reason == v1alpha1.ReasonSourceUnavailable {
+		a = p.merge
+	}
+	return errors.Wrap(a.Apply(ctx, s, resource.ConnectionSecretMustBeControllableBy(mg.GetUID())), errPublishConnection)
+}
+
+// UnpublishConnection is a no-op, because connection secrets are controlled
+// by their DataSource and deleted along with it.
+func (p *connectionPublisher) UnpublishConnection(_ context.Context, _ resource.Managed, _ managed.ConnectionDetails) error {
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestConnectionDetails(t *testing.T) {
+	data := `{"db":{"host":"db.example.org","port":5432,"password":"s3cr3t"},"endpoints":[{"url":"https://a"}]}`
+
+	type want struct {
+		cd  managed.ConnectionDetails
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		cds    []v1alpha1.ConnectionDetail
+		data   string
+		want   want
+	}{
+		"Strings": {
+			reason: "String fields should be published as-is.",
+			cds: []v1alpha1.ConnectionDetail{
+				{Name: "password", FromFieldPath: "db.password"},
+				{Name: "endpoint", FromFieldPath: "endpoints[0].url"},
+			},
+			data: data,
+			want: want{
+				cd: managed.ConnectionDetails{
+					"password": []byte("s3cr3t"),
+					"endpoint": []byte("https://a"),
+				},
+			},
+		},
+		"JSON": {
+			reason: "Fields that are not strings should be published as JSON.",
+			cds: []v1alpha1.ConnectionDetail{
+				{Name: "port", FromFieldPath: "db.port"},
+				{Name: "endpoints", FromFieldPath: "endpoints"},
+			},
+			data: data,
+			want: want{
+				cd: managed.ConnectionDetails{
+					"port":      []byte("5432"),
+					"endpoints": []byte(`[{"url":"https://a"}]`),
+				},
+			},
+		},
+		"MissingField": {
+			reason: "Fields that do not exist should return an error.",
+			cds:    []v1alpha1.ConnectionDetail{{Name: "user", FromFieldPath: "db.user"}},
+			data:   data,
+			want: want{
+				err: errors.Wrapf(errors.New("db.user: no such field"), errFmtConnectionDetail, "user"),
+			},
+		},
+		"NotObject": {
+			reason: "Data that is not an object should return an error.",
+			cds:    []v1alpha1.ConnectionDetail{{Name: "a", FromFieldPath: "a"}},
+			data:   `["a"]`,
+			want: want{
+				err: errors.Wrap(errors.New("json: cannot unmarshal array into Go value of type map[string]interface {}"), errConnectionDetailsObject),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cd, err := connectionDetails(tc.cds, &runtime.RawExtension{Raw: []byte(tc.data)})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nconnectionDetails(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cd, cd); diff != "" {
+				t.Errorf("\n%s\nconnectionDetails(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectionPublisher(t *testing.T) {
+	// existing returns a connection secret controlled by the DataSource
+	// that holds a detail the DataSource no longer has.
+	existing := test.NewMockGetFn(nil, func(obj client.Object) error {
+		obj.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(&xpv1.TypedReference{UID: "ds-uid"})})
+		obj.(*corev1.Secret).Data = map[string][]byte{"endpoint": []byte("a"), "password": []byte("b")}
+		return nil
+	})
+
+	cases := map[string]struct {
+		reason string
+		stale  bool
+		want   map[string][]byte
+		method string
+	}{
+		"DroppedKey": {
+			reason: "Details the DataSource no longer has should be removed from its connection secret.",
+			want:   map[string][]byte{"endpoint": []byte("c")},
+			method: "update",
+		},
+		"Stale": {
+			reason: "Details should be merged into the connection secret while the data of the DataSource is stale.",
+			stale:  true,
+			want:   map[string][]byte{"endpoint": []byte("c")},
+			method: "patch",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got map[string][]byte
+			var method string
+			kube := &test.MockClient{
+				MockGet: existing,
+				MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					got, method = obj.(*corev1.Secret).Data, "update"
+					return nil
+				},
+				MockPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.PatchOption) error {
+					b, err := p.Data(obj)
+					if err != nil {
+						return err
+					}
+					patch := &corev1.Secret{}
+					err = json.Unmarshal(b, patch)
+					got, method = patch.Data, "patch"
+					return err
+				},
+			}
+
+			ds := dataSource("ds", v1alpha1.DataSourceParameters{})
+			ds.SetUID("ds-uid")
+			ds.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Name: "conn", Namespace: "test"})
+			if tc.stale {
+				ds.SetConditions(v1alpha1.SourceUnavailable(errors.New("boom")))
+			}
+
+			s := runtime.NewScheme()
+			if err := v1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+				t.Fatalf("AddToScheme(...): %v", err)
+			}
+			p := newConnectionPublisher(kube, s)
+			if err := p.PublishConnection(context.Background(), &ds, managed.ConnectionDetails{"endpoint": []byte("c")}); err != nil {
+				t.Fatalf("PublishConnection(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.method, method); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -want method, +got method:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPublishConnection(...): -want data, +got data:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
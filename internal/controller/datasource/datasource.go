@@ -18,19 +18,26 @@ package datasource
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
-	"github.com/go-resty/resty/v2"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -46,44 +53,111 @@ const (
 	errNotDataSource = "managed resource is not a DataSource custom resource"
 	errTrackPCUsage  = "cannot track ProviderConfig usage"
 	errGetPC         = "cannot get ProviderConfig"
+	errIndex         = "cannot index DataSource references"
 
-	errConfigMapName = "configMapName must be specified when type is configmap"
-	errURI           = "uri must be specified when type is uri"
+	errConfigMapName = "configMapName or configMapSelector must be specified when type is configmap"
+	errSecretName    = "secretName must be specified when type is secret"
 	errDataLookup    = "cannot retrieve from datasource"
 
+	errNoSource             = "type or sources must be specified"
+	errSourcesAndSource     = "sources cannot be specified along with a top-level type or source"
 	errFmtUnknownSourceType = "unknown datasource type %s"
-	errFmtRequestFailed     = "request failed: %s"
+	errFmtAllSourcesFailed  = "no source could be looked up: %s"
+	errFmtSecretKey         = "secret does not contain key %s"
 )
 
+// Options configures the DataSource controller.
+type Options struct {
+	// CacheTTL is how long responses to URL sources are cached and shared
+	// between DataSources making the same request. Responses are not
+	// cached if it is zero.
+	CacheTTL time.Duration
+
+	// TransformTimeout is how long a transform may be evaluated for.
+	TransformTimeout time.Duration
+
+	// TransformMemoryLimit is how many bytes of heap a transform may use.
+	// Transforms are evaluated in their own process when memory is limited.
+	// Memory is not limited if it is zero.
+	TransformMemoryLimit uint64
+}
+
 // Setup adds a controller that reconciles DataSource managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, do Options) error {
 	name := managed.ControllerName(v1alpha1.DataSourceGroupKind)
 
 	o := controller.Options{
 		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.DataSource{}, referenceIndex, indexReferences); err != nil {
+		return errors.Wrap(err, errIndex)
+	}
+
+	schedule := newRefreshSchedule()
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.DataSourceGroupVersionKind),
 		managed.WithExternalConnecter(&connector{
-			kube:  mgr.GetClient(),
-			usage: resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			kube:     mgr.GetClient(),
+			usage:    resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			cache:    newResponseCache(do.CacheTTL),
+			tokens:   newTokenCache(),
+			clients:  newClientCache(),
+			limits:   transformLimits{timeout: do.TransformTimeout, memory: do.TransformMemoryLimit},
+			schedule: schedule,
 		}),
+		managed.WithConnectionPublishers(newConnectionPublisher(mgr.GetClient(), mgr.GetScheme())),
 		managed.WithLogger(l.WithValues("controller", name)),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o).
-		For(&v1alpha1.DataSource{}).
-		Complete(r)
+		// DataSources write their own status on every reconcile, so we
+		// only react to changes to their spec or metadata.
+		For(&v1alpha1.DataSource{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+		))).
+		Watches(&source.Kind{Type: &apiv1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc((&referenceMapper{
+			kube: mgr.GetClient(),
+			kind: kindConfigMap,
+			log:  l.WithValues("controller", name),
+		}).Map)).
+		Watches(&source.Kind{Type: &apiv1.Secret{}}, handler.EnqueueRequestsFromMapFunc((&referenceMapper{
+			kube: mgr.GetClient(),
+			kind: kindSecret,
+			log:  l.WithValues("controller", name),
+		}).Map)).
+		// DataSources whose variables reference other DataSources are
+		// reconciled when the data or spec of those DataSources changes.
+		Watches(&source.Kind{Type: &v1alpha1.DataSource{}}, handler.EnqueueRequestsFromMapFunc((&referenceMapper{
+			kube: mgr.GetClient(),
+			kind: kindDataSource,
+			log:  l.WithValues("controller", name),
+		}).Map), builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			dataChangedPredicate{},
+		))).
+		// The refresh reconciler requeues each DataSource at the next
+		// refresh time its ExternalClient recorded, because the cache
+		// usually does not yet reflect the status just written.
+		Complete(&refreshReconciler{Reconciler: r, schedule: schedule, now: time.Now})
 }
 
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
-	kube  client.Client
-	usage resource.Tracker
+	kube    client.Client
+	usage   resource.Tracker
+	cache   *responseCache
+	tokens  *tokenCache
+	clients *clientCache
+	limits  transformLimits
+
+	// schedule is shared with the refreshReconciler.
+	schedule *refreshSchedule
 }
 
 // Connect typically produces an ExternalClient by:
@@ -102,9 +176,34 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errGetPC)
 	}
 
+	auth, err := getAuthenticator(ctx, c.kube, pc, c.tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := getHTTPClient(ctx, c.kube, pc, c.clients)
+	if err != nil {
+		return nil, err
+	}
+
+	var tf *transformer
+	if cr.Spec.ForProvider.Transform != nil {
+		if tf, err = compileTransform(*cr.Spec.ForProvider.Transform, c.limits); err != nil {
+			cr.SetConditions(v1alpha1.TransformInvalid(err))
+			return nil, errors.Wrap(err, errCompileTransform)
+		}
+		cr.SetConditions(v1alpha1.TransformCompiled())
+	}
+
 	return &external{
-		client: c.kube,
-		ns:     pc.Spec.Namespace,
+		client:    c.kube,
+		ns:        pc.Spec.Namespace,
+		objectNs:  pc.Spec.ObjectNamespaces,
+		cache:     c.cache,
+		auth:      auth,
+		http:      hc,
+		transform: tf,
+		schedule:  c.schedule,
 	}, nil
 }
 
@@ -113,9 +212,22 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 type external struct {
 	client client.Client
 	ns     string
+	cache  *responseCache
+	auth   authenticator
+	http   *httpClient
+
+	// objectNs are the namespaces kubernetes sources may read objects from
+	// in addition to ns.
+	objectNs []string
+
+	// transform applied to looked up data, if any.
+	transform *transformer
+
+	// schedule records the next refresh time of each observed DataSource.
+	schedule *refreshSchedule
 }
 
-func lookupConfigMap(ctx context.Context, client client.Client, namespace string, name string, re *runtime.RawExtension) error { //nolint:interfacer
+func lookupConfigMap(ctx context.Context, client client.Client, namespace string, name string, d decoder, re *runtime.RawExtension) error { //nolint:interfacer
 	// Interfacer linting disabled as it tries to suggest json.Unmarshaler
 	cm := &apiv1.ConfigMap{}
 	if err := client.Get(ctx, types.NamespacedName{
@@ -124,56 +236,305 @@ func lookupConfigMap(ctx context.Context, client client.Client, namespace string
 	}, cm); err != nil {
 		return err
 	}
-	mb, err := json.Marshal(cm.Data)
+	data, err := configMapData(cm, d)
+	if err != nil {
+		return err
+	}
+	mb, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 	return re.UnmarshalJSON(mb)
 }
 
-func lookupURL(ctx context.Context, uri string, re *runtime.RawExtension) error { //nolint:interfacer
+// configMapData returns the data of the supplied ConfigMap, with its values
+// parsed and its keys filtered and renamed by the supplied decoder. Binary
+// data is base64 encoded, or stored as raw data in raw format.
+func configMapData(cm *apiv1.ConfigMap, d decoder) (interface{}, error) {
+	data, err := d.parseConfigMapData(cm.Data)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range cm.BinaryData {
+		if ok, err := includeKey(d.keys, k); err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if d.format != nil && *d.format == v1alpha1.FormatRaw {
+			data[k] = d.rawValue(v, "")
+			continue
+		}
+		data[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	return renameKeys(d.keys, data)
+}
+
+// lookupSecret writes the decoded values of the exposed keys of a Secret to
+// re, and returns all remaining keys as connection details so that raw secret
+// values are never stored in the status of a DataSource.
+func lookupSecret(ctx context.Context, client client.Client, namespace string, name string, exposed []string, re *runtime.RawExtension) (managed.ConnectionDetails, error) { //nolint:interfacer
 	// Interfacer linting disabled as it tries to suggest json.Unmarshaler
-	c := resty.New()
-	c.SetRetryCount(1)
-	c.SetTimeout(1 * time.Second)
-	c.SetHeader("Accept", "application/json")
+	s := &apiv1.Secret{}
+	if err := client.Get(ctx, types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}, s); err != nil {
+		return nil, err
+	}
+
+	// Secret data is base64 encoded by the API server and decoded by the
+	// client, so values are used as-is here.
+	cd := managed.ConnectionDetails{}
+	for k, v := range s.Data {
+		cd[k] = v
+	}
+
+	data := map[string]string{}
+	for _, k := range exposed {
+		v, ok := cd[k]
+		if !ok {
+			return nil, errors.Errorf(errFmtSecretKey, k)
+		}
+		data[k] = string(v)
+		delete(cd, k)
+	}
 
-	res, err := c.R().
-		SetContext(ctx).
-		Get(uri)
+	mb, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return cd, re.UnmarshalJSON(mb)
+}
 
+// listConfigMaps writes the data of all ConfigMaps matching the supplied
+// selector to re.
+func listConfigMaps(ctx context.Context, kube client.Client, namespace string, ls *metav1.LabelSelector, lf *v1alpha1.ListFormat, d decoder, re *runtime.RawExtension) error {
+	s, err := selectorFor(ls)
 	if err != nil {
 		return err
 	}
 
-	if !res.IsSuccess() {
-		return errors.Errorf(errFmtRequestFailed, res.Status())
+	l := &apiv1.ConfigMapList{}
+	if err := kube.List(ctx, l, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: s}); err != nil {
+		return err
+	}
+
+	vs := make([]namedValue, len(l.Items))
+	for i := range l.Items {
+		data, err := configMapData(&l.Items[i], d)
+		if err != nil {
+			return err
+		}
+		vs[i] = namedValue{name: l.Items[i].GetName(), value: data}
+	}
+	return writeList(vs, lf, re)
+}
+
+// A lookupResult describes the outcome of a successful lookup.
+type lookupResult struct {
+	// connectionDetails to be published for the DataSource.
+	connectionDetails managed.ConnectionDetails
+
+	// notModified is true if the source reported that its data has not
+	// changed since the previous lookup, in which case no data was written.
+	notModified bool
+
+	// validators identifying the version of the data that was looked up.
+	validators validators
+
+	// source the data was looked up from.
+	source v1alpha1.SourceStatus
+
+	// skipped sources that were tried before the source.
+	skipped []v1alpha1.SourceStatus
+
+	// merged is true if the data of all sources was merged, in which case
+	// provenance records the source of each merged key.
+	merged     bool
+	provenance map[string]int
+}
+
+// validatorsOf returns the validators of the data currently recorded in the
+// status of the supplied DataSource. No validators are returned if the spec
+// has changed since the data was recorded, so that the data is always
+// retrieved again.
+func validatorsOf(cr *v1alpha1.DataSource) validators {
+	if cr.Status.AtProvider == nil || cr.Status.ObservedGeneration != cr.GetGeneration() {
+		return validators{}
 	}
+	v := validators{etag: cr.Status.ETag, lastModified: cr.Status.LastModified}
+	if cr.Status.Source != nil {
+		v.source = cr.Status.Source.Index
+	}
+	return v
+}
 
-	return re.UnmarshalJSON(res.Body())
+// recordLookup records the validators and sources of a successful lookup, and
+// the generation of the DataSource it was made for, in the DataSource's
+// status.
+func recordLookup(cr *v1alpha1.DataSource, res lookupResult) {
+	cr.Status.ETag = res.validators.etag
+	cr.Status.LastModified = res.validators.lastModified
+	cr.Status.ObservedGeneration = cr.GetGeneration()
+	recordSources(cr, res)
 }
 
-func lookupData(ctx context.Context, client client.Client, ext external, sp v1alpha1.DataSourceSpec, re *runtime.RawExtension) error {
-	var err error
+// recordSources records the source a successful lookup was made from, and
+// the sources that were skipped, or the provenance of merged data, in the
+// DataSource's status.
+func recordSources(cr *v1alpha1.DataSource, res lookupResult) {
+	cr.Status.Source = nil
+	cr.Status.SkippedSources = nil
+	cr.Status.Provenance = nil
+	if res.merged {
+		cr.Status.Provenance = res.provenance
+		return
+	}
+	src := res.source
+	cr.Status.Source = &src
+	if len(res.skipped) > 0 {
+		cr.Status.SkippedSources = res.skipped
+	}
+}
+
+// sourcesOf returns the sources of the supplied parameters, in the order in
+// which they should be tried.
+func sourcesOf(p v1alpha1.DataSourceParameters) []v1alpha1.SourceParameters {
+	if len(p.Sources) > 0 {
+		return p.Sources
+	}
+	return []v1alpha1.SourceParameters{p.SourceParameters}
+}
 
-	switch sp.ForProvider.SourceType {
+// lookupSource writes the data of the supplied source, with its templated
+// fields rendered, to re.
+func lookupSource(ctx context.Context, client client.Client, ext external, p v1alpha1.DataSourceParameters, src v1alpha1.SourceParameters, v validators, re *runtime.RawExtension) (lookupResult, error) {
+	res := lookupResult{}
+	src, err := renderSource(p, src)
+	if err != nil {
+		return res, err
+	}
+
+	switch src.SourceType {
 	case v1alpha1.SourceTypeConfigMap:
 
-		if sp.ForProvider.ConfigMapName == nil {
-			return errors.New(errConfigMapName)
+		switch {
+		case src.ConfigMapName != nil:
+			err = lookupConfigMap(ctx, client, ext.ns, *src.ConfigMapName, decoderFor(p), re)
+		case src.ConfigMapSelector != nil:
+			err = listConfigMaps(ctx, client, ext.ns, src.ConfigMapSelector, p.ListFormat, decoderFor(p), re)
+		default:
+			return res, errors.New(errConfigMapName)
+		}
+
+	case v1alpha1.SourceTypeSecret:
+		if src.SecretName == nil {
+			return res, errors.New(errSecretName)
 		}
-		err = lookupConfigMap(ctx, client, ext.ns, *sp.ForProvider.ConfigMapName, re)
+		res.connectionDetails, err = lookupSecret(ctx, client, ext.ns, *src.SecretName, src.ExposedKeys, re)
 
 	case v1alpha1.SourceTypeURL:
-		if sp.ForProvider.URL == nil {
-			return errors.New(errURI)
+		if src.URL == nil {
+			return res, errors.New(errURI)
+		}
+		var r request
+		if r, err = buildRequest(ctx, client, ext.ns, *src.URL, src.Request); err != nil {
+			return res, err
 		}
-		err = lookupURL(ctx, *sp.ForProvider.URL, re)
+		if ext.http != nil {
+			ext.http.configure(&r)
+		}
+		if ext.auth != nil {
+			ext.auth.authenticate(&r)
+		}
+		res, err = lookupURL(ctx, ext.cache, r, src.BypassCache, decoderFor(p), v, re)
+
+	case v1alpha1.SourceTypeKubernetes:
+		if src.Object == nil {
+			return res, errors.New(errObject)
+		}
+		err = lookupObject(ctx, client, ext.ns, ext.objectNs, *src.Object, p.ListFormat, re)
+
+	case "":
+		return res, errors.New(errNoSource)
+
 	default:
-		return errors.Errorf(errFmtUnknownSourceType, sp.ForProvider.SourceType)
+		return res, errors.Errorf(errFmtUnknownSourceType, src.SourceType)
+	}
+
+	return res, err
+}
+
+// lookupSources writes the data of the first of the supplied sources that is
+// successfully looked up to re, recording why any sources before it were
+// skipped. The supplied validators are only used for the source they were
+// recorded for.
+func lookupSources(ctx context.Context, client client.Client, ext external, p v1alpha1.DataSourceParameters, v validators, re *runtime.RawExtension) (lookupResult, error) {
+	skipped := []v1alpha1.SourceStatus{}
+	var last error
+	for i, src := range sourcesOf(p) {
+		sv := validators{}
+		if v.source == i {
+			sv = v
+		}
+
+		// Sources write their data to re as they look it up, so each is
+		// looked up into its own document in case it fails part way.
+		nd := runtime.RawExtension{}
+		res, err := lookupSource(ctx, client, ext, p, src, sv, &nd)
+		if err != nil {
+			skipped = append(skipped, v1alpha1.SourceStatus{Index: i, Type: src.SourceType, Reason: err.Error()})
+			last = err
+			continue
+		}
+		if !res.notModified {
+			*re = nd
+		}
+		res.source = v1alpha1.SourceStatus{Index: i, Type: src.SourceType}
+		res.validators.source = i
+		res.skipped = skipped
+		return res, nil
 	}
 
-	return err
+	// A single source's error is returned as-is.
+	if len(skipped) == 1 {
+		return lookupResult{}, last
+	}
+	reasons := make([]string, len(skipped))
+	for i, s := range skipped {
+		reasons[i] = fmt.Sprintf("sources[%d]: %s", s.Index, s.Reason)
+	}
+	return lookupResult{}, errors.Errorf(errFmtAllSourcesFailed, strings.Join(reasons, "; "))
+}
+
+func lookupData(ctx context.Context, client client.Client, ext external, sp v1alpha1.DataSourceSpec, v validators, re *runtime.RawExtension) (lookupResult, error) {
+	// A top-level source would otherwise be silently ignored in favour of
+	// the listed sources.
+	if len(sp.ForProvider.Sources) > 0 && !cmp.Equal(sp.ForProvider.SourceParameters, v1alpha1.SourceParameters{}) {
+		return lookupResult{}, errors.New(errSourcesAndSource)
+	}
+
+	var res lookupResult
+	var err error
+	if sp.ForProvider.Merge != nil {
+		res, err = lookupMerged(ctx, client, ext, sp.ForProvider, re)
+	} else {
+		res, err = lookupSources(ctx, client, ext, sp.ForProvider, v, re)
+	}
+	if err != nil || res.notModified {
+		return res, err
+	}
+	if sp.ForProvider.Extract != nil {
+		if err := extract(*sp.ForProvider.Extract, re); err != nil {
+			return res, err
+		}
+	}
+	if ext.transform != nil {
+		return res, ext.transform.apply(ctx, re)
+	}
+	return res, nil
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -181,32 +542,76 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotDataSource)
 	}
+	defer c.schedule.record(cr)
 
 	// If deletion was requested, return that this resource does not exist
-	// or the Kubernetes API object will not be deleted.
+	// or the Kubernetes API object will not be deleted. Data that was
+	// written to a ConfigMap or Secret must be deleted first.
 	if cr.DeletionTimestamp != nil {
-		return managed.ExternalObservation{ResourceExists: false}, nil
+		o, err := getSink(ctx, c.client, c.ns, cr)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetSink)
+		}
+		return managed.ExternalObservation{ResourceExists: o != nil}, nil
+	}
+
+	// Data that has been refreshed recently enough is up to date without
+	// us having to look it up. Connection details are published on every
+	// path, because they replace the data of the connection secret.
+	now := time.Now()
+	if fresh(cr, now) {
+		cd, err := connectionDetailsOf(cr, nil)
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: cd}, err
 	}
 
 	nd := runtime.RawExtension{}
 
-	err := lookupData(
+	res, err := lookupData(
 		ctx,
 		c.client,
 		*c,
 		cr.Spec,
+		validatorsOf(cr),
 		&nd)
 
 	if err != nil {
-		return managed.ExternalObservation{ResourceExists: false}, errors.Wrap(err, errDataLookup)
+		err = errors.Wrap(err, errDataLookup)
+		kept, serr := keepStale(cr, now, err)
+		if serr != nil {
+			return managed.ExternalObservation{}, serr
+		}
+		if kept {
+			cd, err := connectionDetailsOf(cr, nil)
+			return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: cd}, err
+		}
+		return managed.ExternalObservation{ResourceExists: false}, err
 	}
 
-	upToDate := cmp.Equal(cr.Status.AtProvider, &nd)
+	// A source that reports its data as not modified is up to date without
+	// us having to retrieve or compare the data.
+	upToDate := res.notModified || cmp.Equal(cr.Status.AtProvider, &nd)
+	switch {
+	case res.notModified:
+		recordSources(cr, res)
+	case upToDate:
+		recordLookup(cr, res)
+	}
+	if !upToDate {
+		return managed.ExternalObservation{ResourceExists: cr.Status.AtProvider != nil}, nil
+	}
+	if err := recordRefresh(cr, now); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if err := writeSink(ctx, c.client, c.ns, cr); err != nil {
+		return managed.ExternalObservation{}, err
+	}
 
+	cd, err := connectionDetailsOf(cr, res.connectionDetails)
 	return managed.ExternalObservation{
-		ResourceExists:   cr.Status.AtProvider != nil,
-		ResourceUpToDate: upToDate,
-	}, nil
+		ResourceExists:    cr.Status.AtProvider != nil,
+		ResourceUpToDate:  true,
+		ConnectionDetails: cd,
+	}, err
 }
 
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
@@ -214,14 +619,16 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotDataSource)
 	}
+	defer c.schedule.record(cr)
 
 	nd := runtime.RawExtension{}
 
-	err := lookupData(
+	res, err := lookupData(
 		ctx,
 		c.client,
 		*c,
 		cr.Spec,
+		validators{},
 		&nd)
 
 	if err != nil {
@@ -229,8 +636,16 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	}
 
 	cr.Status.AtProvider = &nd
+	recordLookup(cr, res)
+	if err := recordRefresh(cr, time.Now()); err != nil {
+		return managed.ExternalCreation{}, err
+	}
 
-	return managed.ExternalCreation{}, nil
+	if err := writeSink(ctx, c.client, c.ns, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	cd, err := connectionDetailsOf(cr, res.connectionDetails)
+	return managed.ExternalCreation{ConnectionDetails: cd}, err
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -238,15 +653,34 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotDataSource)
 	}
+	defer c.schedule.record(cr)
 
-	err := lookupData(
+	res, err := lookupData(
 		ctx,
 		c.client,
 		*c,
 		cr.Spec,
+		validatorsOf(cr),
 		cr.Status.AtProvider)
 
-	return managed.ExternalUpdate{}, errors.Wrap(err, errDataLookup)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errDataLookup)
+	}
+
+	if res.notModified {
+		recordSources(cr, res)
+	} else {
+		recordLookup(cr, res)
+	}
+	if err := recordRefresh(cr, time.Now()); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	if err := writeSink(ctx, c.client, c.ns, cr); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	cd, err := connectionDetailsOf(cr, res.connectionDetails)
+	return managed.ExternalUpdate{ConnectionDetails: cd}, err
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -256,5 +690,5 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	}
 	cr.Status.AtProvider = nil
 
-	return nil
+	return deleteSink(ctx, c.client, c.ns, cr)
 }
@@ -18,13 +18,30 @@ package datasource
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -36,16 +53,58 @@ import (
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
 func TestObserve(t *testing.T) {
-	type fields struct{}
+	errBoom := errors.New("boom")
+	errNotFound := kerrors.NewNotFound(schema.GroupResource{}, "")
+	now := time.Now()
+	later := metav1.NewTime(now.Add(time.Hour))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":2}`))
+	}))
+	defer srv.Close()
+
+	url := v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeURL, URL: &srv.URL}}
+	cm := v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeConfigMap, ConfigMapName: pointer.StringPtr("values")}}
+
+	// ds returns a DataSource with the supplied parameters that was last
+	// looked up with the supplied data.
+	ds := func(p v1alpha1.DataSourceParameters, data string, mod ...func(*v1alpha1.DataSource)) *v1alpha1.DataSource {
+		d := dataSource("ds", p)
+		d.SetUID("ds-uid")
+		d.Status.AtProvider = &runtime.RawExtension{Raw: []byte(data)}
+		for _, fn := range mod {
+			fn(&d)
+		}
+		return &d
+	}
+	deleting := func(d *v1alpha1.DataSource) {
+		d.SetDeletionTimestamp(&metav1.Time{Time: now})
+		d.Spec.WriteTo = &v1alpha1.WriteTo{Kind: v1alpha1.SinkKindSecret, Name: "sink"}
+	}
+	values := test.NewMockGetFn(nil, func(obj client.Object) error {
+		if c, ok := obj.(*apiv1.ConfigMap); ok {
+			c.Data = map[string]string{"region": "eu-west-1"}
+		}
+		return nil
+	})
+
+	type fields struct {
+		kube client.Client
+	}
 
 	type args struct {
-		ctx context.Context
-		mg  resource.Managed
+		mg resource.Managed
 	}
 
 	type want struct {
-		o   managed.ExternalObservation
-		err error
+		o     managed.ExternalObservation
+		err   error
+		stale xpv1.ConditionReason
 	}
 
 	cases := map[string]struct {
@@ -54,19 +113,431 @@ func TestObserve(t *testing.T) {
 		args   args
 		want   want
 	}{
-		// TODO: Add test cases.
+		"NotDataSource": {
+			reason: "An error should be returned if the managed resource is not a DataSource.",
+			args:   args{mg: nil},
+			want:   want{err: errors.New(errNotDataSource)},
+		},
+		"DeletingWithSink": {
+			reason: "A DataSource being deleted should exist while the object it writes to exists.",
+			fields: fields{kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				obj.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(&xpv1.TypedReference{UID: "ds-uid"})})
+				return nil
+			})}},
+			args: args{mg: ds(cm, `{}`, deleting)},
+			want: want{o: managed.ExternalObservation{ResourceExists: true}},
+		},
+		"DeletingWithoutSink": {
+			reason: "A DataSource being deleted should not exist once the object it writes to is gone.",
+			fields: fields{kube: &test.MockClient{MockGet: test.NewMockGetFn(errNotFound)}},
+			args:   args{mg: ds(cm, `{}`, deleting)},
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"Fresh": {
+			reason: "Data that is not yet due a refresh should be up to date without being looked up.",
+			args: args{mg: ds(url, `{"version":1}`, func(d *v1alpha1.DataSource) {
+				d.Status.NextRefreshTime = &later
+			})},
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"NotModified": {
+			reason: "Data that the source reports as not modified should be up to date.",
+			args: args{mg: ds(url, `{"version":1}`, func(d *v1alpha1.DataSource) {
+				d.Status.ETag = `"v2"`
+			})},
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"FreshConnectionDetails": {
+			reason: "Connection details should be published from the stored data when it is not looked up.",
+			args: args{mg: ds(url, `{"version":1}`, func(d *v1alpha1.DataSource) {
+				d.Spec.ForProvider.ConnectionDetails = []v1alpha1.ConnectionDetail{{Name: "version", FromFieldPath: "version"}}
+				d.Status.NextRefreshTime = &later
+			})},
+			want: want{o: managed.ExternalObservation{
+				ResourceExists:    true,
+				ResourceUpToDate:  true,
+				ConnectionDetails: managed.ConnectionDetails{"version": []byte("1")},
+			}},
+		},
+		"NotModifiedConnectionDetails": {
+			reason: "Connection details should be published from the stored data when the source reports it as not modified.",
+			args: args{mg: ds(url, `{"version":1}`, func(d *v1alpha1.DataSource) {
+				d.Spec.ForProvider.ConnectionDetails = []v1alpha1.ConnectionDetail{{Name: "version", FromFieldPath: "version"}}
+				d.Status.ETag = `"v2"`
+			})},
+			want: want{o: managed.ExternalObservation{
+				ResourceExists:    true,
+				ResourceUpToDate:  true,
+				ConnectionDetails: managed.ConnectionDetails{"version": []byte("1")},
+			}},
+		},
+		"Modified": {
+			reason: "Data that differs from the data last looked up should not be up to date.",
+			args:   args{mg: ds(url, `{"version":1}`)},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"Unchanged": {
+			reason: "Data that matches the data last looked up should be up to date.",
+			fields: fields{kube: &test.MockClient{MockGet: values}},
+			args:   args{mg: ds(cm, `{"region":"eu-west-1"}`)},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"UnchangedWriteSinkError": {
+			reason: "Up to date data should be written to the configured object.",
+			fields: fields{kube: &test.MockClient{MockGet: values, MockUpdate: test.NewMockUpdateFn(errBoom)}},
+			args: args{mg: ds(cm, `{"region":"eu-west-1"}`, func(d *v1alpha1.DataSource) {
+				d.Spec.WriteTo = &v1alpha1.WriteTo{Name: "sink"}
+			})},
+			want: want{err: errors.Wrap(errors.Wrap(errBoom, "cannot update object"), errWriteSink)},
+		},
+		"SourcesAndSource": {
+			reason: "A top-level source should not be specified along with a list of sources.",
+			args: args{mg: ds(v1alpha1.DataSourceParameters{
+				SourceParameters: url.SourceParameters,
+				Sources:          []v1alpha1.SourceParameters{cm.SourceParameters},
+			}, `{}`)},
+			want: want{err: errors.Wrap(errors.New(errSourcesAndSource), errDataLookup)},
+		},
+		"LookupError": {
+			reason: "Errors looking up data should be returned when no staleness policy is configured.",
+			fields: fields{kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}},
+			args:   args{mg: ds(cm, `{"region":"eu-west-1"}`)},
+			want:   want{err: errors.Wrap(errBoom, errDataLookup)},
+		},
+		"LookupErrorKeepStale": {
+			reason: "Data should be kept, and the DataSource marked stale, when its source is unavailable and a staleness policy is configured.",
+			fields: fields{kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}},
+			args: args{mg: ds(cm, `{"region":"eu-west-1"}`, func(d *v1alpha1.DataSource) {
+				d.Spec.ForProvider.Staleness = &v1alpha1.StalenessPolicy{}
+			})},
+			want: want{
+				o:     managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				stale: v1alpha1.ReasonSourceUnavailable,
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{}
-			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			e := external{client: tc.fields.kube, ns: "test", schedule: newRefreshSchedule()}
+			got, err := e.Observe(context.Background(), tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
 			}
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
+			if cr, ok := tc.args.mg.(*v1alpha1.DataSource); ok {
+				if diff := cmp.Diff(tc.want.stale, cr.GetCondition(v1alpha1.TypeStale).Reason); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want stale reason, +got stale reason:\n%s\n", tc.reason, diff)
+				}
+				next, ok := e.schedule.pop(types.NamespacedName{Name: cr.GetName()})
+				if diff := cmp.Diff(cr.Status.NextRefreshTime != nil, ok); diff != "" {
+					t.Errorf("\n%s\ne.Observe(...): -want next refresh time recorded, +got:\n%s\n", tc.reason, diff)
+				}
+				if ok && !next.Equal(cr.Status.NextRefreshTime.Time) {
+					t.Errorf("\n%s\ne.Observe(...): want next refresh time %s recorded, got %s", tc.reason, cr.Status.NextRefreshTime, next)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":2}`))
+	}))
+	defer srv.Close()
+
+	ds := func(etag string) *v1alpha1.DataSource {
+		d := dataSource("ds", v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeURL, URL: &srv.URL}})
+		d.Status.AtProvider = &runtime.RawExtension{Raw: []byte(`{"version":1}`)}
+		d.Status.ETag = etag
+		return &d
+	}
+
+	type want struct {
+		data string
+		etag string
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.DataSource
+		want   want
+	}{
+		"NotModified": {
+			reason: "Data that the source reports as not modified should be kept.",
+			cr:     ds(`"v2"`),
+			want:   want{data: `{"version":1}`, etag: `"v2"`},
+		},
+		"Modified": {
+			reason: "Modified data should replace the data last looked up, and its validators be recorded.",
+			cr:     ds(`"v1"`),
+			want:   want{data: `{"version":2}`, etag: `"v2"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{ns: "test"}
+			if _, err := e.Update(context.Background(), tc.cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.data, string(tc.cr.Status.AtProvider.Raw)); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want data, +got data:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.etag, tc.cr.Status.ETag); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want ETag, +got ETag:\n%s\n", tc.reason, diff)
+			}
+			if tc.cr.Status.LastRefreshTime == nil {
+				t.Errorf("\n%s\ne.Update(...): want last refresh time to be recorded", tc.reason)
+			}
+		})
+	}
+}
+
+func TestLookupSecret(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	secret := func(obj client.Object) error {
+		s := obj.(*apiv1.Secret)
+		s.Data = map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("hunter2"),
+		}
+		return nil
+	}
+
+	type args struct {
+		kube    client.Client
+		exposed []string
+	}
+
+	type want struct {
+		cd  managed.ConnectionDetails
+		re  *runtime.RawExtension
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"GetError": {
+			reason: "Errors getting the Secret should be returned.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errBoom,
+			},
+		},
+		"NoExposedKeys": {
+			reason: "All keys should be returned as connection details when none are exposed.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, secret)},
+			},
+			want: want{
+				cd: managed.ConnectionDetails{
+					"username": []byte("admin"),
+					"password": []byte("hunter2"),
+				},
+				re: &runtime.RawExtension{Raw: []byte(`{}`)},
+			},
+		},
+		"ExposedKeys": {
+			reason: "Exposed keys should be written to status and omitted from connection details.",
+			args: args{
+				kube:    &test.MockClient{MockGet: test.NewMockGetFn(nil, secret)},
+				exposed: []string{"username"},
+			},
+			want: want{
+				cd: managed.ConnectionDetails{
+					"password": []byte("hunter2"),
+				},
+				re: &runtime.RawExtension{Raw: []byte(`{"username":"admin"}`)},
+			},
+		},
+		"MissingExposedKey": {
+			reason: "Exposing a key the Secret does not contain should return an error.",
+			args: args{
+				kube:    &test.MockClient{MockGet: test.NewMockGetFn(nil, secret)},
+				exposed: []string{"token"},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.Errorf(errFmtSecretKey, "token"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			re := &runtime.RawExtension{}
+			cd, err := lookupSecret(context.Background(), tc.args.kube, "test", "creds", tc.args.exposed, re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nlookupSecret(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cd, cd); diff != "" {
+				t.Errorf("\n%s\nlookupSecret(...): -want connection details, +got connection details:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.re, re); diff != "" {
+				t.Errorf("\n%s\nlookupSecret(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConfigMapData(t *testing.T) {
+	scalars := v1alpha1.CoercionScalars
+	raw := v1alpha1.FormatRaw
+
+	cm := &apiv1.ConfigMap{
+		Data:       map[string]string{"replicas": "3", "name": "payments"},
+		BinaryData: map[string][]byte{"logo": {0x89, 'P', 'N', 'G'}},
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.DataSourceParameters
+		want   string
+	}{
+		"Default": {
+			reason: "Values should be returned as strings, with binary data base64 encoded.",
+			want:   `{"logo":"iVBORw==","name":"payments","replicas":"3"}`,
+		},
+		"Coerced": {
+			reason: "Values should be coerced if requested.",
+			p:      v1alpha1.DataSourceParameters{Coercion: &scalars},
+			want:   `{"logo":"iVBORw==","name":"payments","replicas":3}`,
+		},
+		"Raw": {
+			reason: "Values and binary data should be stored as raw data in raw format.",
+			p:      v1alpha1.DataSourceParameters{Format: &raw},
+			want:   `{"logo":{"base64":"iVBORw==","contentType":"text/plain; charset=utf-8","length":4},"name":{"contentType":"text/plain; charset=utf-8","length":8,"text":"payments"},"replicas":{"contentType":"text/plain; charset=utf-8","length":1,"text":"3"}}`,
+		},
+		"Keys": {
+			reason: "Excluded keys, including binary data keys, should be dropped before the remaining keys are renamed.",
+			p:      v1alpha1.DataSourceParameters{Keys: &v1alpha1.KeyOptions{Exclude: []string{"logo"}, Rename: map[string]string{"name": "app.name"}, Nest: true}},
+			want:   `{"app":{"name":"payments"},"replicas":"3"}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, err := configMapData(cm, decoderFor(tc.p))
+			if err != nil {
+				t.Fatalf("\n%s\nconfigMapData(...): %v", tc.reason, err)
+			}
+			got, _ := json.Marshal(data)
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nconfigMapData(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLookupSources(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// Only the ConfigMap named "defaults" exists.
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			if key.Name != "defaults" {
+				return errBoom
+			}
+			obj.(*apiv1.ConfigMap).Data = map[string]string{"region": "eu-west-1"}
+			return nil
+		},
+	}
+	cm := func(name string) v1alpha1.SourceParameters {
+		return v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeConfigMap, ConfigMapName: &name}
+	}
+
+	type want struct {
+		source  v1alpha1.SourceStatus
+		skipped []v1alpha1.SourceStatus
+		re      *runtime.RawExtension
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.DataSourceParameters
+		want   want
+	}{
+		"Primary": {
+			reason: "The source identified by type should be looked up if no sources are specified.",
+			p:      v1alpha1.DataSourceParameters{SourceParameters: cm("defaults")},
+			want: want{
+				source:  v1alpha1.SourceStatus{Index: 0, Type: v1alpha1.SourceTypeConfigMap},
+				skipped: []v1alpha1.SourceStatus{},
+				re:      &runtime.RawExtension{Raw: []byte(`{"region":"eu-west-1"}`)},
+			},
+		},
+		"PrimaryError": {
+			reason: "The error of a single source should be returned as-is.",
+			p:      v1alpha1.DataSourceParameters{SourceParameters: cm("live")},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errBoom,
+			},
+		},
+		"NoSource": {
+			reason: "An error should be returned if no source is specified.",
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.New(errNoSource),
+			},
+		},
+		"Fallback": {
+			reason: "The first source that is successfully looked up should be used, recording why earlier sources were skipped.",
+			p: v1alpha1.DataSourceParameters{
+				SourceParameters: cm("ignored"),
+				Sources:          []v1alpha1.SourceParameters{cm("live"), cm("defaults"), cm("unused")},
+			},
+			want: want{
+				source:  v1alpha1.SourceStatus{Index: 1, Type: v1alpha1.SourceTypeConfigMap},
+				skipped: []v1alpha1.SourceStatus{{Index: 0, Type: v1alpha1.SourceTypeConfigMap, Reason: "boom"}},
+				re:      &runtime.RawExtension{Raw: []byte(`{"region":"eu-west-1"}`)},
+			},
+		},
+		"AllFailed": {
+			reason: "The errors of every source should be returned if none can be looked up.",
+			p: v1alpha1.DataSourceParameters{
+				Sources: []v1alpha1.SourceParameters{cm("live"), {SourceType: v1alpha1.SourceTypeSecret}},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.Errorf(errFmtAllSourcesFailed, "sources[0]: boom; sources[1]: "+errSecretName),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			re := &runtime.RawExtension{}
+			res, err := lookupSources(context.Background(), kube, external{ns: "test"}, tc.p, validators{}, re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nlookupSources(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.source, res.source); diff != "" {
+				t.Errorf("\n%s\nlookupSources(...): -want source, +got source:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.skipped, res.skipped); diff != "" {
+				t.Errorf("\n%s\nlookupSources(...): -want skipped, +got skipped:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.re, re); diff != "" {
+				t.Errorf("\n%s\nlookupSources(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
 		})
 	}
 }
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errExtract                  = "exactly one of expression or expressions must be specified"
+	errExtractData              = "cannot extract from data"
+	errFmtExpression            = "cannot evaluate expression %q"
+	errFmtNoResult              = "expression %q selected no data"
+	errFmtUnknownExpressionLang = "unknown expression language %s"
+)
+
+// extract replaces the data in re with the parts of it selected by the
+// supplied Extract.
+func extract(e v1alpha1.Extract, re *runtime.RawExtension) error {
+	if (e.Expression == nil) == (len(e.Expressions) == 0) {
+		return errors.New(errExtract)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(re.Raw, &data); err != nil {
+		return errors.Wrap(err, errExtractData)
+	}
+
+	lang := v1alpha1.ExpressionLanguageJSONPath
+	if e.Language != nil {
+		lang = *e.Language
+	}
+
+	var out interface{}
+	if e.Expression != nil {
+		v, err := selectData(lang, *e.Expression, data)
+		if err != nil {
+			return err
+		}
+		out = v
+	} else {
+		// Expressions are evaluated in key order so that the same error is
+		// returned for the same data every time.
+		keys := make([]string, 0, len(e.Expressions))
+		for k := range e.Expressions {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		m := make(map[string]interface{}, len(e.Expressions))
+		for _, k := range keys {
+			v, err := selectData(lang, e.Expressions[k], data)
+			if err != nil {
+				return err
+			}
+			m[k] = v
+		}
+		out = m
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return re.UnmarshalJSON(b)
+}
+
+// selectData returns the data selected by the supplied expression, returning
+// an error if it selects no data.
+func selectData(lang v1alpha1.ExpressionLanguage, expr string, data interface{}) (interface{}, error) {
+	v, err := evaluate(lang, expr, data)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, errors.Errorf(errFmtNoResult, expr)
+	}
+	return v, nil
+}
+
+// evaluate returns the data selected by the supplied expression.
+func evaluate(lang v1alpha1.ExpressionLanguage, expr string, data interface{}) (interface{}, error) {
+	switch lang {
+	case v1alpha1.ExpressionLanguageJSONPath:
+		v, err := evaluateJSONPath(expr, data)
+		return v, errors.Wrapf(err, errFmtExpression, expr)
+	case v1alpha1.ExpressionLanguageJMESPath:
+		v, err := jmespath.Search(expr, data)
+		return v, errors.Wrapf(err, errFmtExpression, expr)
+	}
+	return nil, errors.Errorf(errFmtUnknownExpressionLang, lang)
+}
+
+// evaluateJSONPath evaluates a Kubernetes style JSONPath expression, with or
+// without its enclosing braces. A single selected value is returned as-is,
+// while several selected values are returned as an array.
+func evaluateJSONPath(expr string, data interface{}) (interface{}, error) {
+	if !strings.HasPrefix(strings.TrimSpace(expr), "{") {
+		expr = "{" + expr + "}"
+	}
+
+	j := jsonpath.New("extract")
+	if err := j.Parse(expr); err != nil {
+		return nil, err
+	}
+	results, err := j.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+
+	vs := []interface{}{}
+	for _, r := range results {
+		for _, v := range r {
+			vs = append(vs, v.Interface())
+		}
+	}
+	switch len(vs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return vs[0], nil
+	}
+	return vs, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestExtract(t *testing.T) {
+	data := `{"region":"eu-west-1","clusters":[{"name":"a","size":3},{"name":"b","size":5}]}`
+	jmes := v1alpha1.ExpressionLanguageJMESPath
+
+	type want struct {
+		data string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		e      v1alpha1.Extract
+		want   want
+	}{
+		"NoExpression": {
+			reason: "An expression or expressions must be specified.",
+			want:   want{data: data, err: errors.New(errExtract)},
+		},
+		"JSONPathValue": {
+			reason: "A single value selected by a JSONPath expression should be stored as-is.",
+			e:      v1alpha1.Extract{Expression: pointer.StringPtr(".region")},
+			want:   want{data: `"eu-west-1"`},
+		},
+		"JSONPathValues": {
+			reason: "Several values selected by a JSONPath expression should be stored as an array.",
+			e:      v1alpha1.Extract{Expression: pointer.StringPtr("{.clusters[*].name}")},
+			want:   want{data: `["a","b"]`},
+		},
+		"JSONPathMissing": {
+			reason: "JSONPath expressions selecting missing data should return an error.",
+			e:      v1alpha1.Extract{Expression: pointer.StringPtr(".zone")},
+			want: want{
+				data: data,
+				err:  errors.Wrapf(errors.New("zone is not found"), errFmtExpression, ".zone"),
+			},
+		},
+		"JMESPathExpressions": {
+			reason: "Data selected by each of several JMESPath expressions should be stored under its key.",
+			e: v1alpha1.Extract{
+				Language: &jmes,
+				Expressions: map[string]string{
+					"region": "region",
+					"large":  "clusters[?size > `4`].name",
+				},
+			},
+			want: want{data: `{"large":["b"],"region":"eu-west-1"}`},
+		},
+		"JMESPathExpressionsNoResult": {
+			reason: "Any of several JMESPath expressions selecting no data should return an error.",
+			e: v1alpha1.Extract{
+				Language: &jmes,
+				Expressions: map[string]string{
+					"region": "region",
+					"zone":   "zone",
+				},
+			},
+			want: want{data: data, err: errors.Errorf(errFmtNoResult, "zone")},
+		},
+		"JMESPathNoResult": {
+			reason: "A single JMESPath expression selecting no data should return an error.",
+			e:      v1alpha1.Extract{Language: &jmes, Expression: pointer.StringPtr("zone")},
+			want:   want{data: data, err: errors.Errorf(errFmtNoResult, "zone")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			re := &runtime.RawExtension{Raw: []byte(data)}
+			err := extract(tc.e, re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nextract(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, string(re.Raw)); diff != "" {
+				t.Errorf("\n%s\nextract(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
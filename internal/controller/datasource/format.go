@@ -0,0 +1,325 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"github.com/magiconair/properties"
+	"github.com/pkg/errors"
+	"gopkg.in/ini.v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errFmtParse         = "cannot parse data as %s"
+	errFmtParseKey      = "cannot parse key %s"
+	errFmtUnknownFormat = "unknown format %s"
+	errCSVRow           = "CSV row has more fields than the header row"
+)
+
+// formatsByMediaType are the formats of the media types of URL responses.
+var formatsByMediaType = map[string]v1alpha1.Format{
+	"application/json":          v1alpha1.FormatJSON,
+	"text/json":                 v1alpha1.FormatJSON,
+	"application/yaml":          v1alpha1.FormatYAML,
+	"application/x-yaml":        v1alpha1.FormatYAML,
+	"text/yaml":                 v1alpha1.FormatYAML,
+	"text/x-yaml":               v1alpha1.FormatYAML,
+	"application/toml":          v1alpha1.FormatTOML,
+	"application/xml":           v1alpha1.FormatXML,
+	"text/xml":                  v1alpha1.FormatXML,
+	"text/html":                 v1alpha1.FormatHTML,
+	"application/xhtml+xml":     v1alpha1.FormatHTML,
+	"text/csv":                  v1alpha1.FormatCSV,
+	"text/x-java-properties":    v1alpha1.FormatProperties,
+	"text/x-java-properties-ng": v1alpha1.FormatProperties,
+}
+
+// formatsByExtension are the formats of the file extensions of ConfigMap
+// keys.
+var formatsByExtension = map[string]v1alpha1.Format{
+	".json":       v1alpha1.FormatJSON,
+	".yaml":       v1alpha1.FormatYAML,
+	".yml":        v1alpha1.FormatYAML,
+	".toml":       v1alpha1.FormatTOML,
+	".ini":        v1alpha1.FormatINI,
+	".env":        v1alpha1.FormatDotenv,
+	".properties": v1alpha1.FormatProperties,
+	".csv":        v1alpha1.FormatCSV,
+	".xml":        v1alpha1.FormatXML,
+	".html":       v1alpha1.FormatHTML,
+	".htm":        v1alpha1.FormatHTML,
+}
+
+// formatForContentType returns the format of a URL response with the
+// supplied Content-Type, which defaults to JSON.
+func formatForContentType(ct string) v1alpha1.Format {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return v1alpha1.FormatJSON
+	}
+	if f, ok := formatsByMediaType[mt]; ok {
+		return f
+	}
+	switch {
+	case strings.HasSuffix(mt, "+yaml"):
+		return v1alpha1.FormatYAML
+	case strings.HasSuffix(mt, "+toml"):
+		return v1alpha1.FormatTOML
+	case strings.HasSuffix(mt, "+xml"):
+		return v1alpha1.FormatXML
+	}
+	return v1alpha1.FormatJSON
+}
+
+// formatForKey returns the format of a ConfigMap value with the supplied key,
+// if its file extension is recognised.
+func formatForKey(key string) (v1alpha1.Format, bool) {
+	f, ok := formatsByExtension[strings.ToLower(path.Ext(key))]
+	return f, ok
+}
+
+// A decoder parses looked up data.
+type decoder struct {
+	// format of the data. JSON is assumed for URL responses, and ConfigMap
+	// values are not parsed, if it is nil.
+	format *v1alpha1.Format
+
+	xml  *v1alpha1.XMLOptions
+	html *v1alpha1.HTMLOptions
+	raw  *v1alpha1.RawOptions
+
+	// coercion of ConfigMap values that are not otherwise parsed.
+	coercion v1alpha1.Coercion
+
+	// keys of ConfigMaps to include, and their names.
+	keys *v1alpha1.KeyOptions
+}
+
+// decoderFor returns the decoder configured by the supplied parameters.
+func decoderFor(p v1alpha1.DataSourceParameters) decoder {
+	d := decoder{format: p.Format, xml: p.XML, html: p.HTML, raw: p.Raw, coercion: v1alpha1.CoercionNone, keys: p.Keys}
+	if p.Coercion != nil {
+		d.coercion = *p.Coercion
+	}
+	return d
+}
+
+// parse parses data in the supplied format into a value that may be
+// marshalled as JSON.
+func (d decoder) parse(f v1alpha1.Format, data []byte) (interface{}, error) {
+	var v interface{}
+	var err error
+	switch f {
+	case v1alpha1.FormatJSON:
+		err = json.Unmarshal(data, &v)
+	case v1alpha1.FormatYAML:
+		v, err = parseYAML(data)
+	case v1alpha1.FormatTOML:
+		m := map[string]interface{}{}
+		err = toml.Unmarshal(data, &m)
+		v = m
+	case v1alpha1.FormatINI:
+		v, err = parseINI(data)
+	case v1alpha1.FormatDotenv:
+		v, err = godotenv.Parse(bytes.NewReader(data))
+	case v1alpha1.FormatProperties:
+		var p *properties.Properties
+		if p, err = properties.Load(data, properties.UTF8); err == nil {
+			v = p.Map()
+		}
+	case v1alpha1.FormatCSV:
+		v, err = parseCSV(data)
+	case v1alpha1.FormatXML:
+		v, err = parseXML(data, d.xml)
+	case v1alpha1.FormatHTML:
+		v, err = parseHTML(data, d.html)
+	case v1alpha1.FormatRaw:
+		v = d.rawValue(data, "")
+	default:
+		return nil, errors.Errorf(errFmtUnknownFormat, f)
+	}
+	return v, errors.Wrapf(err, errFmtParse, f)
+}
+
+// rawValue returns data without parsing it, along with its content type and
+// length. Text is returned as a string, or as an array of lines, while other
+// data is base64 encoded. The content type is detected from the data if it
+// is not supplied.
+func (d decoder) rawValue(data []byte, contentType string) interface{} {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	out := map[string]interface{}{
+		"contentType": contentType,
+		"length":      len(data),
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		out["base64"] = base64.StdEncoding.EncodeToString(data)
+		return out
+	}
+
+	o := v1alpha1.RawOptions{}
+	if d.raw != nil {
+		o = *d.raw
+	}
+	text := string(data)
+	if o.TrimSpace {
+		text = strings.TrimSpace(text)
+	}
+	if !o.SplitLines {
+		out["text"] = text
+		return out
+	}
+
+	lines := []string{}
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSuffix(l, "\r")
+		if o.TrimSpace {
+			l = strings.TrimSpace(l)
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	out["lines"] = lines
+	return out
+}
+
+// parseYAML parses a stream of YAML documents. A single document is returned
+// as-is, while several documents are returned as an array.
+func parseYAML(data []byte) (interface{}, error) {
+	docs := []interface{}{}
+	r := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		var v interface{}
+		if err := yaml.Unmarshal(doc, &v); err != nil {
+			return nil, err
+		}
+		docs = append(docs, v)
+	}
+	if len(docs) == 1 {
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+// parseINI parses an INI file into an object with a key for each section.
+// Keys outside of any section are returned at the top level.
+func parseINI(data []byte) (interface{}, error) {
+	f, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	for _, s := range f.Sections() {
+		if s.Name() == ini.DefaultSection {
+			for k, v := range s.KeysHash() {
+				out[k] = v
+			}
+			continue
+		}
+		out[s.Name()] = s.KeysHash()
+	}
+	return out, nil
+}
+
+// parseCSV parses a CSV file with a header row into an array of objects keyed
+// by column name.
+func parseCSV(data []byte) (interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	out := []map[string]string{}
+	if len(rows) == 0 {
+		return out, nil
+	}
+	header := rows[0]
+	for _, row := range rows[1:] {
+		if len(row) > len(header) {
+			return nil, errors.New(errCSVRow)
+		}
+		m := make(map[string]string, len(row))
+		for i, v := range row {
+			m[header[i]] = v
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// parseConfigMapData parses the values of the included keys of a ConfigMap.
+// Values are parsed in the decoder's format if it has one. When the format is
+// auto, only values whose keys have a recognised file extension are parsed.
+// All other values are coerced.
+func (d decoder) parseConfigMapData(data map[string]string) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if ok, err := includeKey(d.keys, k); err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var kf v1alpha1.Format
+		if d.format != nil {
+			kf = *d.format
+		}
+		if kf == v1alpha1.FormatAuto {
+			kf, _ = formatForKey(k)
+		}
+		if kf == "" {
+			out[k] = coerce(d.coercion, v)
+			continue
+		}
+		pv, err := d.parse(kf, []byte(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtParseKey, k)
+		}
+		out[k] = pv
+	}
+	return out, nil
+}
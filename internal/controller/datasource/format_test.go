@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		f       v1alpha1.Format
+		data    string
+		want    string
+		wantErr bool
+	}{
+		"JSON": {
+			reason: "JSON documents should be parsed.",
+			f:      v1alpha1.FormatJSON,
+			data:   `{"a":1}`,
+			want:   `{"a":1}`,
+		},
+		"YAML": {
+			reason: "A single YAML document should be parsed as-is.",
+			f:      v1alpha1.FormatYAML,
+			data:   "a: 1\nb: [x, z]\n",
+			want:   `{"a":1,"b":["x","z"]}`,
+		},
+		"YAMLStream": {
+			reason: "A stream of YAML documents should be parsed into an array.",
+			f:      v1alpha1.FormatYAML,
+			data:   "---\na: 1\n---\nb: 2\n",
+			want:   `[{"a":1},{"b":2}]`,
+		},
+		"TOML": {
+			reason: "TOML documents should be parsed.",
+			f:      v1alpha1.FormatTOML,
+			data:   "name = \"payments\"\n[db]\nport = 5432\n",
+			want:   `{"db":{"port":5432},"name":"payments"}`,
+		},
+		"INI": {
+			reason: "INI files should be parsed with a key for each section.",
+			f:      v1alpha1.FormatINI,
+			data:   "name = payments\n[db]\nhost = db.example.org\n",
+			want:   `{"db":{"host":"db.example.org"},"name":"payments"}`,
+		},
+		"Dotenv": {
+			reason: ".env files should be parsed.",
+			f:      v1alpha1.FormatDotenv,
+			data:   "# comment\nDB_HOST=db.example.org\nexport DB_PORT=\"5432\"\n",
+			want:   `{"DB_HOST":"db.example.org","DB_PORT":"5432"}`,
+		},
+		"Properties": {
+			reason: "Java .properties files should be parsed.",
+			f:      v1alpha1.FormatProperties,
+			data:   "db.host = db.example.org\ndb.port: 5432\n",
+			want:   `{"db.host":"db.example.org","db.port":"5432"}`,
+		},
+		"CSV": {
+			reason: "CSV files should be parsed into an array of objects keyed by column name.",
+			f:      v1alpha1.FormatCSV,
+			data:   "id,region\na,eu\nb,us\n",
+			want:   `[{"id":"a","region":"eu"},{"id":"b","region":"us"}]`,
+		},
+		"CSVTooManyFields": {
+			reason:  "CSV rows with more fields than the header row should be rejected.",
+			f:       v1alpha1.FormatCSV,
+			data:    "id\na,eu\n",
+			wantErr: true,
+		},
+		"Invalid": {
+			reason:  "Data that cannot be parsed should return an error.",
+			f:       v1alpha1.FormatTOML,
+			data:    "not = [toml",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := decoder{}.parse(tc.f, []byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nparse(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			got, _ := json.Marshal(v)
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nparse(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFormatForContentType(t *testing.T) {
+	cases := map[string]v1alpha1.Format{
+		"application/json; charset=utf-8": v1alpha1.FormatJSON,
+		"application/x-yaml":              v1alpha1.FormatYAML,
+		"application/vnd.config+yaml":     v1alpha1.FormatYAML,
+		"text/csv":                        v1alpha1.FormatCSV,
+		"text/plain":                      v1alpha1.FormatJSON,
+		"":                                v1alpha1.FormatJSON,
+	}
+	for ct, want := range cases {
+		if diff := cmp.Diff(want, formatForContentType(ct)); diff != "" {
+			t.Errorf("formatForContentType(%q): -want, +got:\n%s\n", ct, diff)
+		}
+	}
+}
+
+func TestParseConfigMapData(t *testing.T) {
+	data := map[string]string{
+		"app.yaml":  "replicas: 3\n",
+		"db.env":    "DB_HOST=db.example.org\n",
+		"motd":      "hello",
+		"notes.txt": "a: b",
+	}
+
+	auto := v1alpha1.FormatAuto
+	got, err := decoder{format: &auto}.parseConfigMapData(data)
+	if err != nil {
+		t.Fatalf("parseConfigMapData(...): %v", err)
+	}
+	b, _ := json.Marshal(got)
+	want := `{"app.yaml":{"replicas":3},"db.env":{"DB_HOST":"db.example.org"},"motd":"hello","notes.txt":"a: b"}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("parseConfigMapData(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	auto := v1alpha1.FormatAuto
+	re := &runtime.RawExtension{}
+	res := &response{body: []byte("region: eu\n"), contentType: "application/yaml"}
+
+	if err := (decoder{format: &auto}).decodeBody(res, re); err != nil {
+		t.Fatalf("decodeBody(...): %v", err)
+	}
+	if diff := cmp.Diff(`{"region":"eu"}`, string(re.Raw)); diff != "" {
+		t.Errorf("decodeBody(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestRawValue(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      *v1alpha1.RawOptions
+		data   []byte
+		ct     string
+		want   string
+	}{
+		"Text": {
+			reason: "Text should be stored as-is with its content type and length.",
+			data:   []byte("v1.2.3\n"),
+			ct:     "text/plain",
+			want:   `{"contentType":"text/plain","length":7,"text":"v1.2.3\n"}`,
+		},
+		"TrimSpace": {
+			reason: "Whitespace should be trimmed from text if requested.",
+			o:      &v1alpha1.RawOptions{TrimSpace: true},
+			data:   []byte("  v1.2.3\n"),
+			ct:     "text/plain",
+			want:   `{"contentType":"text/plain","length":9,"text":"v1.2.3"}`,
+		},
+		"SplitLines": {
+			reason: "Text should be split into its non-empty lines if requested.",
+			o:      &v1alpha1.RawOptions{TrimSpace: true, SplitLines: true},
+			data:   []byte("10.0.0.0/8\r\n\n 192.168.0.0/16 \n"),
+			ct:     "text/plain",
+			want:   `{"contentType":"text/plain","length":30,"lines":["10.0.0.0/8","192.168.0.0/16"]}`,
+		},
+		"Binary": {
+			reason: "Binary data should be base64 encoded, with its content type detected.",
+			data:   []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00},
+			want:   `{"base64":"iVBORw0KGgoA","contentType":"image/png","length":9}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, _ := json.Marshal(decoder{raw: tc.o}.rawValue(tc.data, tc.ct))
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nrawValue(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errFmtKeyPattern  = "invalid key pattern %s"
+	errFmtKeyConflict = "key %s conflicts with another key"
+
+	defaultKeySeparator = "."
+)
+
+// nestedKeys is an object created by nesting keys. It is distinct from
+// objects that are values, so that keys are never nested into values.
+type nestedKeys map[string]interface{}
+
+// includeKey returns true if the supplied key is included by the supplied
+// options. All keys are included if the options are nil.
+func includeKey(o *v1alpha1.KeyOptions, k string) (bool, error) {
+	if o == nil {
+		return true, nil
+	}
+	included := len(o.Include) == 0
+	for _, p := range o.Include {
+		ok, err := path.Match(p, k)
+		if err != nil {
+			return false, errors.Wrapf(err, errFmtKeyPattern, p)
+		}
+		included = included || ok
+	}
+	for _, p := range o.Exclude {
+		ok, err := path.Match(p, k)
+		if err != nil {
+			return false, errors.Wrapf(err, errFmtKeyPattern, p)
+		}
+		if ok {
+			return false, nil
+		}
+	}
+	return included, nil
+}
+
+// renameKeys strips the prefix of, renames and nests the keys of the supplied
+// data, as configured by the supplied options.
+func renameKeys(o *v1alpha1.KeyOptions, data map[string]interface{}) (map[string]interface{}, error) {
+	if o == nil {
+		return data, nil
+	}
+
+	renamed := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if o.StripPrefix != nil {
+			k = strings.TrimPrefix(k, *o.StripPrefix)
+		}
+		if n, ok := o.Rename[k]; ok {
+			k = n
+		}
+		if _, exists := renamed[k]; exists {
+			return nil, errors.Errorf(errFmtKeyConflict, k)
+		}
+		renamed[k] = v
+	}
+	if !o.Nest {
+		return renamed, nil
+	}
+
+	sep := defaultKeySeparator
+	if o.Separator != nil && *o.Separator != "" {
+		sep = *o.Separator
+	}
+
+	// Keys are nested in order so that conflicts are reported consistently.
+	keys := make([]string, 0, len(renamed))
+	for k := range renamed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := nestedKeys{}
+	for _, k := range keys {
+		if !nestKey(out, strings.Split(k, sep), renamed[k]) {
+			return nil, errors.Errorf(errFmtKeyConflict, k)
+		}
+	}
+	return out, nil
+}
+
+// nestKey sets the supplied value at the supplied path of nested keys,
+// creating any objects it passes through. It returns false if the path
+// conflicts with a key that has already been set.
+func nestKey(obj nestedKeys, p []string, v interface{}) bool {
+	for _, k := range p[:len(p)-1] {
+		existing, ok := obj[k]
+		if !ok {
+			child := nestedKeys{}
+			obj[k] = child
+			obj = child
+			continue
+		}
+		child, ok := existing.(nestedKeys)
+		if !ok {
+			return false
+		}
+		obj = child
+	}
+	leaf := p[len(p)-1]
+	if _, ok := obj[leaf]; ok {
+		return false
+	}
+	obj[leaf] = v
+	return true
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestIncludeKey(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		o       *v1alpha1.KeyOptions
+		k       string
+		want    bool
+		wantErr bool
+	}{
+		"NoOptions": {
+			reason: "All keys should be included without options.",
+			k:      "db.host",
+			want:   true,
+		},
+		"Included": {
+			reason: "Keys matching an include pattern should be included.",
+			o:      &v1alpha1.KeyOptions{Include: []string{"db.*"}},
+			k:      "db.host",
+			want:   true,
+		},
+		"NotIncluded": {
+			reason: "Keys matching no include pattern should not be included.",
+			o:      &v1alpha1.KeyOptions{Include: []string{"db.*"}},
+			k:      "cache.host",
+			want:   false,
+		},
+		"Excluded": {
+			reason: "Exclude patterns should take precedence over include patterns.",
+			o:      &v1alpha1.KeyOptions{Include: []string{"db.*"}, Exclude: []string{"*.password"}},
+			k:      "db.password",
+			want:   false,
+		},
+		"BadPattern": {
+			reason:  "Invalid patterns should return an error.",
+			o:       &v1alpha1.KeyOptions{Include: []string{"db.["}},
+			k:       "db.host",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := includeKey(tc.o, tc.k)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nincludeKey(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nincludeKey(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	prefix := "app."
+	sep := "_"
+
+	cases := map[string]struct {
+		reason string
+		o      *v1alpha1.KeyOptions
+		data   map[string]interface{}
+		want   string
+		err    error
+	}{
+		"NoOptions": {
+			reason: "Keys should be unchanged without options.",
+			data:   map[string]interface{}{"db.host": "a"},
+			want:   `{"db.host":"a"}`,
+		},
+		"StripPrefix": {
+			reason: "Prefixes should be stripped from keys.",
+			o:      &v1alpha1.KeyOptions{StripPrefix: &prefix},
+			data:   map[string]interface{}{"app.db.host": "a", "other": "b"},
+			want:   `{"db.host":"a","other":"b"}`,
+		},
+		"Rename": {
+			reason: "Keys should be renamed after their prefix is stripped.",
+			o:      &v1alpha1.KeyOptions{StripPrefix: &prefix, Rename: map[string]string{"db.host": "hostname"}},
+			data:   map[string]interface{}{"app.db.host": "a"},
+			want:   `{"hostname":"a"}`,
+		},
+		"RenameConflict": {
+			reason: "Renaming a key to an existing key should return an error.",
+			o:      &v1alpha1.KeyOptions{Rename: map[string]string{"a": "b"}},
+			data:   map[string]interface{}{"a": "1", "b": "2"},
+			err:    errors.Errorf(errFmtKeyConflict, "b"),
+		},
+		"Nest": {
+			reason: "Dotted keys should be expanded into nested objects.",
+			o:      &v1alpha1.KeyOptions{Nest: true},
+			data:   map[string]interface{}{"db.host": "a", "db.port": 5432, "name": "b"},
+			want:   `{"db":{"host":"a","port":5432},"name":"b"}`,
+		},
+		"NestSeparator": {
+			reason: "Keys should be nested using the configured separator.",
+			o:      &v1alpha1.KeyOptions{Nest: true, Separator: &sep},
+			data:   map[string]interface{}{"DB_HOST": "a", "db.port": "b"},
+			want:   `{"DB":{"HOST":"a"},"db.port":"b"}`,
+		},
+		"NestConflict": {
+			reason: "Nesting a key inside a value should return an error.",
+			o:      &v1alpha1.KeyOptions{Nest: true},
+			data:   map[string]interface{}{"db": map[string]interface{}{"host": "a"}, "db.port": "b"},
+			err:    errors.Errorf(errFmtKeyConflict, "db.port"),
+		},
+		"NestLeafConflict": {
+			reason: "Replacing a nested object with a value should return an error.",
+			o:      &v1alpha1.KeyOptions{Nest: true},
+			data:   map[string]interface{}{"db.host": "a", "db.host.": "b"},
+			err:    errors.Errorf(errFmtKeyConflict, "db.host."),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, err := renameKeys(tc.o, tc.data)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nrenameKeys(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if tc.err != nil {
+				return
+			}
+			got, _ := json.Marshal(data)
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nrenameKeys(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
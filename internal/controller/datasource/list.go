@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errSelector           = "cannot parse label selector"
+	errFmtUnknownListType = "unknown list format %s"
+)
+
+// A namedValue is the data retrieved from a single object matched by a
+// label selector.
+type namedValue struct {
+	name  string
+	value interface{}
+}
+
+// selectorFor converts the supplied LabelSelector into a labels.Selector.
+func selectorFor(ls *metav1.LabelSelector) (labels.Selector, error) {
+	s, err := metav1.LabelSelectorAsSelector(ls)
+	return s, errors.Wrap(err, errSelector)
+}
+
+// writeList writes the supplied values to re in the supplied format. Values
+// are ordered by name so that repeated lookups of the same objects always
+// produce identical output, regardless of the order the API server returns
+// them in.
+func writeList(vs []namedValue, f *v1alpha1.ListFormat, re *runtime.RawExtension) error { //nolint:interfacer
+	// Interfacer linting disabled as it tries to suggest json.Unmarshaler
+	sort.SliceStable(vs, func(i, j int) bool { return vs[i].name < vs[j].name })
+
+	format := v1alpha1.ListFormatArray
+	if f != nil {
+		format = *f
+	}
+
+	var out interface{}
+	switch format {
+	case v1alpha1.ListFormatArray:
+		a := make([]interface{}, len(vs))
+		for i := range vs {
+			a[i] = vs[i].value
+		}
+		out = a
+	case v1alpha1.ListFormatMap:
+		m := make(map[string]interface{}, len(vs))
+		for _, v := range vs {
+			m[v.name] = v.value
+		}
+		out = m
+	default:
+		return errors.Errorf(errFmtUnknownListType, format)
+	}
+
+	mb, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return re.UnmarshalJSON(mb)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestWriteList(t *testing.T) {
+	format := func(f v1alpha1.ListFormat) *v1alpha1.ListFormat { return &f }
+	values := func() []namedValue {
+		return []namedValue{
+			{name: "b", value: map[string]string{"team": "payments"}},
+			{name: "a", value: map[string]string{"team": "identity"}},
+		}
+	}
+
+	type args struct {
+		vs []namedValue
+		f  *v1alpha1.ListFormat
+	}
+
+	type want struct {
+		re  *runtime.RawExtension
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DefaultArray": {
+			reason: "Values should be returned as an array ordered by name when no format is supplied.",
+			args: args{
+				vs: values(),
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`[{"team":"identity"},{"team":"payments"}]`)},
+			},
+		},
+		"Map": {
+			reason: "Values should be returned keyed by name when the map format is supplied.",
+			args: args{
+				vs: values(),
+				f:  format(v1alpha1.ListFormatMap),
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`{"a":{"team":"identity"},"b":{"team":"payments"}}`)},
+			},
+		},
+		"Empty": {
+			reason: "An empty array should be returned when no objects match.",
+			args: args{
+				vs: []namedValue{},
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`[]`)},
+			},
+		},
+		"UnknownFormat": {
+			reason: "An unknown format should return an error.",
+			args: args{
+				vs: values(),
+				f:  format("csv"),
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.Errorf(errFmtUnknownListType, "csv"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			re := &runtime.RawExtension{}
+			err := writeList(tc.args.vs, tc.args.f, re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nwriteList(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.re, re); diff != "" {
+				t.Errorf("\n%s\nwriteList(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errXMLRoot             = "XML document has no root element"
+	errHTMLSelectors       = "html.selectors must be specified to parse HTML"
+	errHTMLSelector        = "exactly one of xpath or css must be specified"
+	errFmtHTMLSelector     = "invalid selector %s"
+	defaultAttributePrefix = "@"
+	defaultTextKey         = "#text"
+)
+
+// parseXML converts an XML document into an object with a single key; the
+// name of its root element.
+func parseXML(data []byte, o *v1alpha1.XMLOptions) (interface{}, error) {
+	prefix, text := defaultAttributePrefix, defaultTextKey
+	if o != nil && o.AttributePrefix != nil {
+		prefix = *o.AttributePrefix
+	}
+	if o != nil && o.TextKey != nil {
+		text = *o.TextKey
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = charset.NewReaderLabel
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil, errors.New(errXMLRoot)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := t.(xml.StartElement); ok {
+			v, err := parseXMLElement(d, se, prefix, text)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{se.Name.Local: v}, nil
+		}
+	}
+}
+
+// parseXMLElement converts the element started by the supplied token. It
+// returns a string if the element has neither attributes nor child elements,
+// and an object otherwise.
+func parseXMLElement(d *xml.Decoder, start xml.StartElement, prefix, textKey string) (interface{}, error) {
+	obj := map[string]interface{}{}
+	for _, a := range start.Attr {
+		obj[prefix+a.Name.Local] = a.Value
+	}
+
+	text := &strings.Builder{}
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			child, err := parseXMLElement(d, t, prefix, textKey)
+			if err != nil {
+				return nil, err
+			}
+			addXMLChild(obj, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				obj[textKey] = s
+			}
+			return obj, nil
+		}
+	}
+}
+
+// addXMLChild adds a child element to the supplied object. Repeated child
+// elements are collected into an array.
+func addXMLChild(obj map[string]interface{}, name string, child interface{}) {
+	existing, ok := obj[name]
+	if !ok {
+		obj[name] = child
+		return
+	}
+	if a, ok := existing.([]interface{}); ok {
+		obj[name] = append(a, child)
+		return
+	}
+	obj[name] = []interface{}{existing, child}
+}
+
+// parseHTML selects values from an HTML document using the configured
+// selectors, returning an object keyed by output key.
+func parseHTML(data []byte, o *v1alpha1.HTMLOptions) (interface{}, error) {
+	if o == nil || len(o.Selectors) == 0 {
+		return nil, errors.New(errHTMLSelectors)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{}, len(o.Selectors))
+	for k, s := range o.Selectors {
+		nodes, err := selectHTML(doc, s)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtHTMLSelector, k)
+		}
+
+		vs := make([]interface{}, len(nodes))
+		for i, n := range nodes {
+			vs[i] = htmlValue(n, s.Attribute)
+		}
+
+		switch {
+		case s.All:
+			out[k] = vs
+		case len(vs) > 0:
+			out[k] = vs[0]
+		default:
+			out[k] = nil
+		}
+	}
+	return out, nil
+}
+
+// selectHTML returns the nodes of the supplied document selected by the
+// supplied selector.
+func selectHTML(doc *html.Node, s v1alpha1.HTMLSelector) ([]*html.Node, error) {
+	switch {
+	case s.XPath != nil && s.CSS == nil:
+		return htmlquery.QueryAll(doc, *s.XPath)
+	case s.CSS != nil && s.XPath == nil:
+		sel, err := cascadia.Compile(*s.CSS)
+		if err != nil {
+			return nil, err
+		}
+		return sel.MatchAll(doc), nil
+	}
+	return nil, errors.New(errHTMLSelector)
+}
+
+// htmlValue returns the value of the supplied attribute of the supplied node,
+// or its trimmed text content if no attribute is supplied.
+func htmlValue(n *html.Node, attr *string) interface{} {
+	if attr == nil {
+		return strings.TrimSpace(htmlquery.InnerText(n))
+	}
+	for _, a := range n.Attr {
+		if a.Key == *attr {
+			return a.Val
+		}
+	}
+	return nil
+}
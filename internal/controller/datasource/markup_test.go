@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestParseXML(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="ISO-8859-1"?>
+<regions default="eu">
+  <!-- Regions we deploy to. -->
+  <region id="eu">Europe</region>
+  <region id="us">United States</region>
+  <owner>platform</owner>
+</regions>`
+
+	cases := map[string]struct {
+		reason string
+		o      *v1alpha1.XMLOptions
+		data   string
+		want   string
+		err    error
+	}{
+		"Defaults": {
+			reason: "XML should be converted using the default attribute and text conventions.",
+			data:   doc,
+			want:   `{"regions":{"@default":"eu","owner":"platform","region":[{"#text":"Europe","@id":"eu"},{"#text":"United States","@id":"us"}]}}`,
+		},
+		"Configured": {
+			reason: "XML should be converted using the configured attribute and text conventions.",
+			o:      &v1alpha1.XMLOptions{AttributePrefix: pointer.StringPtr("-"), TextKey: pointer.StringPtr("value")},
+			data:   doc,
+			want:   `{"regions":{"-default":"eu","owner":"platform","region":[{"-id":"eu","value":"Europe"},{"-id":"us","value":"United States"}]}}`,
+		},
+		"NoRoot": {
+			reason: "Documents without a root element should return an error.",
+			data:   `<?xml version="1.0"?>`,
+			err:    errors.New(errXMLRoot),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := parseXML([]byte(tc.data), tc.o)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nparseXML(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			got, _ := json.Marshal(v)
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nparseXML(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	doc := `<html><body>
+<h1 class="version"> v1.2.3 </h1>
+<ul id="mirrors">
+  <li><a href="https://eu.example.org">EU</a></li>
+  <li><a href="https://us.example.org">US</a></li>
+</ul>
+</body></html>`
+
+	cases := map[string]struct {
+		reason string
+		o      *v1alpha1.HTMLOptions
+		want   string
+		err    error
+	}{
+		"NoSelectors": {
+			reason: "Selectors must be configured to parse HTML.",
+			err:    errors.New(errHTMLSelectors),
+		},
+		"Selectors": {
+			reason: "Values should be selected using XPath and CSS selectors.",
+			o: &v1alpha1.HTMLOptions{Selectors: map[string]v1alpha1.HTMLSelector{
+				"version": {CSS: pointer.StringPtr("h1.version")},
+				"mirrors": {XPath: pointer.StringPtr("//ul[@id='mirrors']//a"), Attribute: pointer.StringPtr("href"), All: true},
+				"first":   {CSS: pointer.StringPtr("#mirrors a")},
+				"missing": {CSS: pointer.StringPtr("h2")},
+			}},
+			want: `{"first":"EU","mirrors":["https://eu.example.org","https://us.example.org"],"missing":null,"version":"v1.2.3"}`,
+		},
+		"InvalidSelector": {
+			reason: "Selectors must specify exactly one of xpath or css.",
+			o: &v1alpha1.HTMLOptions{Selectors: map[string]v1alpha1.HTMLSelector{
+				"version": {},
+			}},
+			err: errors.Wrapf(errors.New(errHTMLSelector), errFmtHTMLSelector, "version"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := parseHTML([]byte(doc), tc.o)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nparseHTML(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			got, _ := json.Marshal(v)
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("\n%s\nparseHTML(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
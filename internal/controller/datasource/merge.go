@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errFmtMergeSource = "cannot merge sources[%d]"
+)
+
+// A merger merges documents, recording the source each merged value came
+// from.
+type merger struct {
+	strategy v1alpha1.MergeStrategy
+	arrays   v1alpha1.ArrayMerge
+
+	provenance map[string]int
+}
+
+func newMerger(o v1alpha1.MergeOptions) *merger {
+	m := &merger{strategy: o.Strategy, arrays: o.Arrays, provenance: map[string]int{}}
+	if m.strategy == "" {
+		m.strategy = v1alpha1.MergeDeep
+	}
+	if m.arrays == "" {
+		m.arrays = v1alpha1.ArrayMergeReplace
+	}
+	return m
+}
+
+// merge the supplied document from the supplied source into the supplied
+// document, returning the merged document.
+func (m *merger) merge(dst, src interface{}, source int) interface{} {
+	return m.mergeAt("", dst, src, source, true)
+}
+
+func (m *merger) mergeAt(path string, dst, src interface{}, source int, top bool) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		d, ok := dst.(map[string]interface{})
+		if !ok || (!top && m.strategy == v1alpha1.MergeShallow) {
+			break
+		}
+		for k, v := range s {
+			existing, ok := d[k]
+			if !ok {
+				d[k] = v
+				m.record(keyPath(path, k), source)
+				continue
+			}
+			d[k] = m.mergeAt(keyPath(path, k), existing, v, source, false)
+		}
+		return d
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok || m.arrays != v1alpha1.ArrayMergeAppend {
+			break
+		}
+		m.record(path, source)
+		return append(d, s...)
+	}
+
+	// The root of a document is not recorded, so the keys of a document
+	// that replaces it are recorded instead.
+	m.record(path, source)
+	if s, ok := src.(map[string]interface{}); ok && top {
+		for k := range s {
+			m.record(keyPath(path, k), source)
+		}
+	}
+	return src
+}
+
+// record that the value at the supplied path, and everything beneath it, came
+// from the supplied source. The root of a document is not recorded.
+func (m *merger) record(path string, source int) {
+	for p := range m.provenance {
+		if path == "" || strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[") {
+			delete(m.provenance, p)
+		}
+	}
+	if path != "" {
+		m.provenance[path] = source
+	}
+}
+
+// keyPath returns the path of the supplied key of the object at the supplied
+// path. Keys that cannot be appended with a dot are enclosed in brackets.
+func keyPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return path + "[" + key + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lookupMerged writes the data of all of the supplied sources, merged in
+// order, to re.
+func lookupMerged(ctx context.Context, client client.Client, ext external, p v1alpha1.DataSourceParameters, re *runtime.RawExtension) (lookupResult, error) {
+	res := lookupResult{merged: true}
+	m := newMerger(*p.Merge)
+
+	var doc interface{}
+	for i, src := range sourcesOf(p) {
+		// Validators are not used, because every source's data is needed
+		// to produce the merged document.
+		nd := runtime.RawExtension{}
+		sr, err := lookupSource(ctx, client, ext, p, src, validators{}, &nd)
+		if err != nil {
+			return lookupResult{}, errors.Wrapf(err, errFmtMergeSource, i)
+		}
+
+		// Numbers are decoded as such so that they are not rounded.
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(nd.Raw))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return lookupResult{}, errors.Wrapf(err, errFmtMergeSource, i)
+		}
+		doc = m.merge(doc, v, i)
+
+		for k, v := range sr.connectionDetails {
+			if res.connectionDetails == nil {
+				res.connectionDetails = managed.ConnectionDetails{}
+			}
+			res.connectionDetails[k] = v
+		}
+	}
+	res.provenance = m.provenance
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return lookupResult{}, err
+	}
+	return res, re.UnmarshalJSON(b)
+}
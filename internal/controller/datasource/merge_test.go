@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestMerge(t *testing.T) {
+	org := `{"db":{"host":"org.example.org","port":5432},"features":["a"],"owner":"platform"}`
+	env := `{"db":{"host":"env.example.org"},"features":["b"],"replicas":3}`
+	live := `{"db":{"port":6543},"replicas":12345678901234567890}`
+
+	type want struct {
+		doc        string
+		provenance map[string]int
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      v1alpha1.MergeOptions
+		docs   []string
+		want   want
+	}{
+		"Deep": {
+			reason: "Objects should be merged recursively, with later sources taking precedence and arrays replaced.",
+			docs:   []string{org, env, live},
+			want: want{
+				doc: `{"db":{"host":"env.example.org","port":6543},"features":["b"],"owner":"platform","replicas":12345678901234567890}`,
+				provenance: map[string]int{
+					"db":       0,
+					"db.host":  1,
+					"db.port":  2,
+					"features": 1,
+					"owner":    0,
+					"replicas": 2,
+				},
+			},
+		},
+		"Shallow": {
+			reason: "Only top-level keys should be merged.",
+			o:      v1alpha1.MergeOptions{Strategy: v1alpha1.MergeShallow},
+			docs:   []string{org, env},
+			want: want{
+				doc: `{"db":{"host":"env.example.org"},"features":["b"],"owner":"platform","replicas":3}`,
+				provenance: map[string]int{
+					"db":       1,
+					"features": 1,
+					"owner":    0,
+					"replicas": 1,
+				},
+			},
+		},
+		"Append": {
+			reason: "Arrays should be appended to when configured.",
+			o:      v1alpha1.MergeOptions{Arrays: v1alpha1.ArrayMergeAppend},
+			docs:   []string{org, env},
+			want: want{
+				doc: `{"db":{"host":"env.example.org","port":5432},"features":["a","b"],"owner":"platform","replicas":3}`,
+				provenance: map[string]int{
+					"db":       0,
+					"db.host":  1,
+					"features": 1,
+					"owner":    0,
+					"replicas": 1,
+				},
+			},
+		},
+		"ReplacedObject": {
+			reason: "Values replacing objects should replace the provenance of their keys.",
+			docs:   []string{org, `{"db":"postgres://db"}`},
+			want: want{
+				doc: `{"db":"postgres://db","features":["a"],"owner":"platform"}`,
+				provenance: map[string]int{
+					"db":       1,
+					"features": 0,
+					"owner":    0,
+				},
+			},
+		},
+		"DottedKeys": {
+			reason: "Keys containing dots should be enclosed in brackets.",
+			docs:   []string{`{"labels":{"app.kubernetes.io/name":"a"}}`},
+			want: want{
+				doc:        `{"labels":{"app.kubernetes.io/name":"a"}}`,
+				provenance: map[string]int{"labels": 0},
+			},
+		},
+		"NewDottedKey": {
+			reason: "Keys containing dots that are merged into objects should be enclosed in brackets.",
+			docs:   []string{`{"labels":{}}`, `{"labels":{"app.kubernetes.io/name":"a"}}`},
+			want: want{
+				doc:        `{"labels":{"app.kubernetes.io/name":"a"}}`,
+				provenance: map[string]int{"labels": 0, "labels[app.kubernetes.io/name]": 1},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := newMerger(tc.o)
+			var doc interface{}
+			for i, d := range tc.docs {
+				var v interface{}
+				dec := json.NewDecoder(bytes.NewReader([]byte(d)))
+				dec.UseNumber()
+				if err := dec.Decode(&v); err != nil {
+					t.Fatal(err)
+				}
+				doc = m.merge(doc, v, i)
+			}
+			got, _ := json.Marshal(doc)
+			if diff := cmp.Diff(tc.want.doc, string(got)); diff != "" {
+				t.Errorf("\n%s\nmerge(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.provenance, m.provenance); diff != "" {
+				t.Errorf("\n%s\nmerge(...): -want provenance, +got provenance:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLookupMerged(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			switch key.Name {
+			case "org":
+				obj.(*apiv1.ConfigMap).Data = map[string]string{"region": "eu-west-1", "owner": "platform"}
+			case "env":
+				obj.(*apiv1.ConfigMap).Data = map[string]string{"region": "us-east-1"}
+			default:
+				return errBoom
+			}
+			return nil
+		},
+	}
+	cm := func(name string) v1alpha1.SourceParameters {
+		return v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeConfigMap, ConfigMapName: &name}
+	}
+
+	type want struct {
+		res lookupResult
+		re  *runtime.RawExtension
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		sources []v1alpha1.SourceParameters
+		want    want
+	}{
+		"Merged": {
+			reason:  "The data of all sources should be merged in order.",
+			sources: []v1alpha1.SourceParameters{cm("org"), cm("env")},
+			want: want{
+				res: lookupResult{merged: true, provenance: map[string]int{"owner": 0, "region": 1}},
+				re:  &runtime.RawExtension{Raw: []byte(`{"owner":"platform","region":"us-east-1"}`)},
+			},
+		},
+		"SourceError": {
+			reason:  "An error should be returned if any source cannot be looked up.",
+			sources: []v1alpha1.SourceParameters{cm("org"), cm("live")},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.Wrapf(errBoom, errFmtMergeSource, 1),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := v1alpha1.DataSourceParameters{Sources: tc.sources, Merge: &v1alpha1.MergeOptions{}}
+			re := &runtime.RawExtension{}
+			res, err := lookupMerged(context.Background(), kube, external{ns: "test"}, p, re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nlookupMerged(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res, res, cmp.AllowUnexported(lookupResult{}, validators{})); diff != "" {
+				t.Errorf("\n%s\nlookupMerged(...): -want result, +got result:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.re, re); diff != "" {
+				t.Errorf("\n%s\nlookupMerged(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
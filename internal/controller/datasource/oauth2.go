@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+const (
+	errOAuth2       = "oauth2 must be specified when the credentials type is OAuth2"
+	errOAuth2Client = "oauth2 clientID or clientIDSecretRef must be specified"
+	errGetClientID  = "cannot get oauth2 client ID"
+	errGetToken     = "cannot get oauth2 access token"
+)
+
+// tokenRefreshMargin is how long before they expire access tokens are
+// refreshed, so that requests are never made with a token that expires
+// while they are in flight.
+const tokenRefreshMargin = 1 * time.Minute
+
+// A tokenSource retrieves OAuth2 access tokens using the client credentials
+// flow, and reuses them until they are about to expire.
+type tokenSource struct {
+	// id identifies the client credentials and scopes the tokens are
+	// retrieved with, without revealing the client secret.
+	id  string
+	cfg clientcredentials.Config
+	now func() time.Time
+
+	mu  sync.Mutex
+	tok *oauth2.Token
+}
+
+// authenticate configures the supplied request to be authenticated using
+// access tokens from this tokenSource.
+func (ts *tokenSource) authenticate(r *request) {
+	r.Auth = ts.id
+	r.tokens = ts
+}
+
+// Token returns the current access token, retrieving a new one if there is
+// none or it is about to expire.
+func (ts *tokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.tok != nil && (ts.tok.Expiry.IsZero() || ts.now().Add(tokenRefreshMargin).Before(ts.tok.Expiry)) {
+		return ts.tok, nil
+	}
+
+	tok, err := ts.cfg.Token(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetToken)
+	}
+	ts.tok = tok
+	return tok, nil
+}
+
+// Invalidate discards the supplied access token if it is the current token,
+// for example because it was rejected before it expired.
+func (ts *tokenSource) Invalidate(tok *oauth2.Token) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.tok == tok {
+		ts.tok = nil
+	}
+}
+
+// A tokenCache stores a tokenSource for each ProviderConfig, so that access
+// tokens are shared by every DataSource using the ProviderConfig rather than
+// retrieved on every reconcile.
+type tokenCache struct {
+	mu      sync.Mutex
+	sources map[string]*tokenSource
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{sources: map[string]*tokenSource{}}
+}
+
+// Get returns the tokenSource of the named ProviderConfig, replacing it if
+// the supplied configuration differs from the one it was created with.
+func (c *tokenCache) Get(name string, cfg clientcredentials.Config) *tokenSource {
+	// Marshalling a struct of strings cannot fail.
+	b, _ := json.Marshal(cfg)
+	h := sha256.Sum256(b)
+	id := hex.EncodeToString(h[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ts, ok := c.sources[name]; ok && ts.id == id {
+		return ts
+	}
+	ts := &tokenSource{id: id, cfg: cfg, now: time.Now}
+	c.sources[name] = ts
+	return ts
+}
+
+// getTokenSource returns the tokenSource configured by the supplied
+// ProviderConfig, using the supplied client secret.
+func getTokenSource(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig, secret string, tokens *tokenCache) (*tokenSource, error) {
+	o := pc.Spec.Credentials.OAuth2
+	if o == nil {
+		return nil, errors.New(errOAuth2)
+	}
+
+	var id string
+	switch {
+	case o.ClientID != nil:
+		id = *o.ClientID
+	case o.ClientIDSecretRef != nil:
+		b, err := resource.ExtractSecret(ctx, kube, xpv1.CommonCredentialSelectors{SecretRef: o.ClientIDSecretRef})
+		if err != nil {
+			return nil, errors.Wrap(err, errGetClientID)
+		}
+		id = strings.TrimSpace(string(b))
+	default:
+		return nil, errors.New(errOAuth2Client)
+	}
+
+	return tokens.Get(pc.GetName(), clientcredentials.Config{
+		ClientID:     id,
+		ClientSecret: secret,
+		TokenURL:     o.TokenURL,
+		Scopes:       o.Scopes,
+	}), nil
+}
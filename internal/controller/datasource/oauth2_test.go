@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// newTokenServer returns a server that issues a new access token, valid for
+// one hour, on every request to /token, and that only accepts requests to
+// /data authenticated with the token accepted returns.
+func newTokenServer(issued *int32, accepted func() string) *httptest.Server {
+	return httptest.NewServer(tokenHandler(issued, accepted))
+}
+
+// tokenHandler returns the handler of a server returned by newTokenServer.
+func tokenHandler(issued *int32, accepted func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			n := atomic.AddInt32(issued, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, n)
+		case "/data":
+			if r.Header.Get("Authorization") != "Bearer "+accepted() {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"authenticated":true}`))
+		}
+	})
+}
+
+func TestTokenSource(t *testing.T) {
+	var issued int32
+	srv := newTokenServer(&issued, func() string { return "" })
+	defer srv.Close()
+
+	now := time.Now()
+	ts := newTokenCache().Get("default", clientcredentials.Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.URL + "/token"})
+	ts.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	first, err := ts.Token(ctx)
+	if err != nil {
+		t.Fatalf("Token(...): %v", err)
+	}
+	if second, _ := ts.Token(ctx); second != first {
+		t.Errorf("Token(...): want an unexpired token to be reused")
+	}
+
+	now = now.Add(time.Hour - tokenRefreshMargin + time.Second)
+	if third, _ := ts.Token(ctx); third == first {
+		t.Errorf("Token(...): want a token to be refreshed before it expires")
+	}
+
+	if diff := cmp.Diff(int32(2), atomic.LoadInt32(&issued)); diff != "" {
+		t.Errorf("Token(...): -want tokens issued, +got tokens issued:\n%s", diff)
+	}
+}
+
+func TestTokenCache(t *testing.T) {
+	c := newTokenCache()
+	cfg := clientcredentials.Config{ClientID: "id", ClientSecret: "secret", TokenURL: "https://example.org/token"}
+
+	ts := c.Get("default", cfg)
+	if c.Get("default", cfg) != ts {
+		t.Errorf("Get(...): want the token source of an unchanged ProviderConfig to be reused")
+	}
+
+	cfg.ClientSecret = "rotated"
+	if c.Get("default", cfg) == ts {
+		t.Errorf("Get(...): want a new token source when the client credentials change")
+	}
+	if c.Get("other", cfg) == c.Get("default", cfg) {
+		t.Errorf("Get(...): want each ProviderConfig to have its own token source")
+	}
+}
+
+func TestDoRequestOAuth2(t *testing.T) {
+	// Only the second token issued is accepted, as if the first had been
+	// revoked before it expired.
+	var issued int32
+	srv := newTokenServer(&issued, func() string { return "token-2" })
+	defer srv.Close()
+
+	r := request{Method: http.MethodGet, URL: srv.URL + "/data"}
+	newTokenCache().Get("default", clientcredentials.Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.URL + "/token"}).authenticate(&r)
+
+	res, err := doRequest(context.Background(), r, validators{})
+	if err != nil {
+		t.Fatalf("doRequest(...): %v", err)
+	}
+	if diff := cmp.Diff(`{"authenticated":true}`, string(res.body)); diff != "" {
+		t.Errorf("doRequest(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff(int32(2), atomic.LoadInt32(&issued)); diff != "" {
+		t.Errorf("doRequest(...): -want tokens issued, +got tokens issued:\n%s", diff)
+	}
+}
+
+func TestDoRequestOAuth2PrivateCA(t *testing.T) {
+	// The token endpoint is served using a certificate that is only trusted
+	// by the client the request is made with.
+	var issued int32
+	srv := httptest.NewTLSServer(tokenHandler(&issued, func() string { return "token-1" }))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	r := request{Method: http.MethodGet, URL: srv.URL + "/data", client: newRestyClient(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil)}
+	newTokenCache().Get("default", clientcredentials.Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.URL + "/token"}).authenticate(&r)
+
+	res, err := doRequest(context.Background(), r, validators{})
+	if err != nil {
+		t.Fatalf("doRequest(...): %v", err)
+	}
+	if diff := cmp.Diff(`{"authenticated":true}`, string(res.body)); diff != "" {
+		t.Errorf("doRequest(...): -want body, +got body:\n%s", diff)
+	}
+}
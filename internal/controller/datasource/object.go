@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errObject          = "object must be specified when type is kubernetes"
+	errObjectSecret    = "secrets cannot be retrieved when type is kubernetes, use type secret instead"
+	errObjectName      = "object name or selector must be specified when type is kubernetes"
+	errGetObject       = "cannot get object"
+	errListObjects     = "cannot list objects"
+	errFmtObjectFields = "cannot get field path %s"
+	errFmtObjectNs     = "objects in namespace %s cannot be read using this ProviderConfig"
+)
+
+// isSecret returns true if the supplied GroupVersionKind is a core Secret.
+// Secrets must be retrieved using the secret source type, which prevents
+// their raw values from being written to the status of a DataSource.
+func isSecret(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "" && gvk.Kind == "Secret"
+}
+
+// objectValue returns the supplied object, or the sub-tree of it selected by
+// the supplied field path.
+func objectValue(u *unstructured.Unstructured, fp *string) (interface{}, error) {
+	// Managed fields change on every write to the object and are of no use
+	// to consumers of the data, so we drop them to avoid needless updates.
+	u.SetManagedFields(nil)
+
+	if fp == nil {
+		return u.Object, nil
+	}
+	v, err := fieldpath.Pave(u.Object).GetValue(*fp)
+	return v, errors.Wrapf(err, errFmtObjectFields, *fp)
+}
+
+// lookupObject retrieves an arbitrary Kubernetes object, or a sub-tree of it,
+// and writes it to re. All objects matching the selector are retrieved when
+// the object has a selector rather than a name. Objects may only be read from
+// the supplied namespace, or from one of the supplied allowed namespaces.
+func lookupObject(ctx context.Context, kube client.Client, namespace string, allowed []string, o v1alpha1.KubernetesObject, f *v1alpha1.ListFormat, re *runtime.RawExtension) error { //nolint:interfacer
+	// Interfacer linting disabled as it tries to suggest json.Unmarshaler
+	gvk := schema.FromAPIVersionAndKind(o.APIVersion, o.Kind)
+	if isSecret(gvk) {
+		return errors.New(errObjectSecret)
+	}
+
+	if o.Namespace != nil && *o.Namespace != namespace {
+		if !namespaceAllowed(*o.Namespace, allowed) {
+			return errors.Errorf(errFmtObjectNs, *o.Namespace)
+		}
+		namespace = *o.Namespace
+	}
+
+	if o.Name == "" {
+		if o.Selector == nil {
+			return errors.New(errObjectName)
+		}
+		return listObjects(ctx, kube, namespace, gvk, o, f, re)
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := kube.Get(ctx, types.NamespacedName{
+		Name:      o.Name,
+		Namespace: namespace,
+	}, u); err != nil {
+		return errors.Wrap(err, errGetObject)
+	}
+
+	v, err := objectValue(u, o.FieldPath)
+	if err != nil {
+		return err
+	}
+
+	mb, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return re.UnmarshalJSON(mb)
+}
+
+// namespaceAllowed returns true if the supplied namespace is one of the
+// supplied allowed namespaces.
+func namespaceAllowed(namespace string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// listObjects writes all objects of the supplied kind matching the selector of
+// the supplied object, or the sub-trees of them, to re.
+func listObjects(ctx context.Context, kube client.Client, namespace string, gvk schema.GroupVersionKind, o v1alpha1.KubernetesObject, f *v1alpha1.ListFormat, re *runtime.RawExtension) error {
+	s, err := selectorFor(o.Selector)
+	if err != nil {
+		return err
+	}
+
+	l := &unstructured.UnstructuredList{}
+	l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := kube.List(ctx, l, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: s}); err != nil {
+		return errors.Wrap(err, errListObjects)
+	}
+
+	vs := make([]namedValue, len(l.Items))
+	for i := range l.Items {
+		v, err := objectValue(&l.Items[i], o.FieldPath)
+		if err != nil {
+			return err
+		}
+		vs[i] = namedValue{name: l.Items[i].GetName(), value: v}
+	}
+	return writeList(vs, f, re)
+}
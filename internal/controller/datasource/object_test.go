@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestLookupObject(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	service := func(obj client.Object) error {
+		u := obj.(*unstructured.Unstructured)
+		u.Object["spec"] = map[string]interface{}{
+			"clusterIP": "10.0.0.1",
+		}
+		return nil
+	}
+
+	type args struct {
+		kube    client.Client
+		allowed []string
+		o       v1alpha1.KubernetesObject
+	}
+
+	type want struct {
+		re  *runtime.RawExtension
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Secret": {
+			reason: "Secrets should not be retrievable as arbitrary objects.",
+			args: args{
+				o: v1alpha1.KubernetesObject{APIVersion: "v1", Kind: "Secret", Name: "creds"},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.New(errObjectSecret),
+			},
+		},
+		"NoNameOrSelector": {
+			reason: "Either a name or a selector must be supplied.",
+			args: args{
+				o: v1alpha1.KubernetesObject{APIVersion: "v1", Kind: "Service"},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.New(errObjectName),
+			},
+		},
+		"GetError": {
+			reason: "Errors getting the object should be returned.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				o:    v1alpha1.KubernetesObject{APIVersion: "v1", Kind: "Service", Name: "api"},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.Wrap(errBoom, errGetObject),
+			},
+		},
+		"WholeObject": {
+			reason: "The whole object should be returned when no field path is supplied.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, service)},
+				o:    v1alpha1.KubernetesObject{APIVersion: "v1", Kind: "Service", Name: "api"},
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Service","spec":{"clusterIP":"10.0.0.1"}}`)},
+			},
+		},
+		"FieldPath": {
+			reason: "Only the selected sub-tree should be returned when a field path is supplied.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil, service)},
+				o: v1alpha1.KubernetesObject{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Name:       "api",
+					FieldPath:  pointer.StringPtr("spec.clusterIP"),
+				},
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`"10.0.0.1"`)},
+			},
+		},
+		"OtherNamespace": {
+			reason: "Objects in namespaces the ProviderConfig does not allow should not be retrieved.",
+			args: args{
+				o: v1alpha1.KubernetesObject{APIVersion: "v1", Kind: "Service", Name: "api", Namespace: pointer.StringPtr("kube-system")},
+			},
+			want: want{
+				re:  &runtime.RawExtension{},
+				err: errors.Errorf(errFmtObjectNs, "kube-system"),
+			},
+		},
+		"AllowedNamespace": {
+			reason: "Objects in namespaces the ProviderConfig allows should be retrieved.",
+			args: args{
+				kube: &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					if key.Namespace != "platform" {
+						return errBoom
+					}
+					return service(obj)
+				}},
+				allowed: []string{"platform"},
+				o: v1alpha1.KubernetesObject{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Name:       "api",
+					Namespace:  pointer.StringPtr("platform"),
+					FieldPath:  pointer.StringPtr("spec.clusterIP"),
+				},
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`"10.0.0.1"`)},
+			},
+		},
+		"Selector": {
+			reason: "The selected sub-tree of every matching object should be returned when a selector is supplied.",
+			args: args{
+				kube: &test.MockClient{MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					l := obj.(*unstructured.UnstructuredList)
+					for _, n := range []string{"web", "api"} {
+						u := unstructured.Unstructured{Object: map[string]interface{}{}}
+						u.SetName(n)
+						u.SetLabels(map[string]string{"team": "payments"})
+						l.Items = append(l.Items, u)
+					}
+					return nil
+				})},
+				o: v1alpha1.KubernetesObject{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+					FieldPath:  pointer.StringPtr("metadata.name"),
+				},
+			},
+			want: want{
+				re: &runtime.RawExtension{Raw: []byte(`["api","web"]`)},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			re := &runtime.RawExtension{}
+			err := lookupObject(context.Background(), tc.args.kube, "test", tc.args.allowed, tc.args.o, nil, re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nlookupObject(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.re, re); diff != "" {
+				t.Errorf("\n%s\nlookupObject(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
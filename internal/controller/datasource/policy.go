@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+const (
+	errFmtCIDR          = "cannot parse CIDR %s"
+	errFmtSchemeDenied  = "scheme %s is not allowed by the ProviderConfig URL policy"
+	errFmtHostDenied    = "host %s is not allowed by the ProviderConfig URL policy"
+	errFmtAddressDenied = "address %s is not allowed by the ProviderConfig URL policy"
+)
+
+// privateNetworks are the ranges denied by denyPrivateNetworks in addition to
+// loopback, link-local and unspecified addresses.
+var privateNetworks = mustParseCIDRs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"fc00::/7",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return nets
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtCIDR, c)
+		}
+		nets[i] = n
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHost returns true if the supplied host matches any of the supplied
+// patterns, which may have a leading wildcard.
+func matchesHost(patterns []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if strings.HasPrefix(p, "*.") {
+			if strings.HasSuffix(host, p[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// A urlPolicy enforces a ProviderConfig's URLPolicy.
+type urlPolicy struct {
+	schemes      []string
+	allowedHosts []string
+	deniedHosts  []string
+	allowedNets  []*net.IPNet
+	deniedNets   []*net.IPNet
+	denyPrivate  bool
+}
+
+// newURLPolicy returns the urlPolicy described by the supplied URLPolicy, or
+// nil if it is nil.
+func newURLPolicy(p *apisv1alpha1.URLPolicy) (*urlPolicy, error) {
+	if p == nil {
+		return nil, nil
+	}
+	allowed, err := parseCIDRs(p.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := parseCIDRs(p.DeniedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	return &urlPolicy{
+		schemes:      p.AllowedSchemes,
+		allowedHosts: p.AllowedHosts,
+		deniedHosts:  p.DeniedHosts,
+		allowedNets:  allowed,
+		deniedNets:   denied,
+		denyPrivate:  p.DenyPrivateNetworks,
+	}, nil
+}
+
+// checkURL returns an error if the scheme or host of the supplied URL is not
+// allowed.
+func (p *urlPolicy) checkURL(u *url.URL) error {
+	if len(p.schemes) > 0 {
+		ok := false
+		for _, s := range p.schemes {
+			ok = ok || strings.EqualFold(s, u.Scheme)
+		}
+		if !ok {
+			return errors.Errorf(errFmtSchemeDenied, u.Scheme)
+		}
+	}
+
+	h := u.Hostname()
+	if matchesHost(p.deniedHosts, h) || (len(p.allowedHosts) > 0 && !matchesHost(p.allowedHosts, h)) {
+		return errors.Errorf(errFmtHostDenied, h)
+	}
+	return nil
+}
+
+// checkIP returns an error if connections to the supplied address are not
+// allowed.
+func (p *urlPolicy) checkIP(ip net.IP) error {
+	switch {
+	case containsIP(p.deniedNets, ip):
+		return errors.Errorf(errFmtAddressDenied, ip)
+	case containsIP(p.allowedNets, ip):
+		return nil
+	case p.denyPrivate && (ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || containsIP(privateNetworks, ip)):
+		return errors.Errorf(errFmtAddressDenied, ip)
+	case len(p.allowedNets) > 0:
+		return errors.Errorf(errFmtAddressDenied, ip)
+	}
+	return nil
+}
+
+// control is called by a net.Dialer after an address has been resolved and
+// before it is connected to, and returns an error if the address is not
+// allowed.
+func (p *urlPolicy) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return errors.Errorf(errFmtAddressDenied, host)
+	}
+	return p.checkIP(ip)
+}
+
+// A policyTransport checks the URL of every request it makes, including
+// those made when following redirects, against a urlPolicy.
+type policyTransport struct {
+	policy    *urlPolicy
+	transport http.RoundTripper
+}
+
+func (t *policyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := t.policy.checkURL(r.URL); err != nil {
+		return nil, err
+	}
+	return t.transport.RoundTrip(r)
+}
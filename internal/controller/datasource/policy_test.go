@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+func TestURLPolicyCheckURL(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      apisv1alpha1.URLPolicy
+		u      string
+		want   error
+	}{
+		"NoRestrictions": {
+			reason: "Any URL should be allowed by an empty policy.",
+			u:      "http://example.org/data",
+		},
+		"SchemeDenied": {
+			reason: "URLs using a scheme that is not allowed should be denied.",
+			p:      apisv1alpha1.URLPolicy{AllowedSchemes: []string{"https"}},
+			u:      "http://example.org/data",
+			want:   errors.Errorf(errFmtSchemeDenied, "http"),
+		},
+		"HostAllowedByWildcard": {
+			reason: "Subdomains of a wildcard allowed host should be allowed.",
+			p:      apisv1alpha1.URLPolicy{AllowedHosts: []string{"*.example.org"}},
+			u:      "https://config.Example.org/data",
+		},
+		"HostNotAllowed": {
+			reason: "Hosts that are not allowed should be denied.",
+			p:      apisv1alpha1.URLPolicy{AllowedHosts: []string{"*.example.org"}},
+			u:      "https://example.net/data",
+			want:   errors.Errorf(errFmtHostDenied, "example.net"),
+		},
+		"HostDenied": {
+			reason: "Denied hosts should be denied even if they are allowed.",
+			p:      apisv1alpha1.URLPolicy{AllowedHosts: []string{"*.example.org"}, DeniedHosts: []string{"admin.example.org"}},
+			u:      "https://admin.example.org/data",
+			want:   errors.Errorf(errFmtHostDenied, "admin.example.org"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := newURLPolicy(&tc.p)
+			if err != nil {
+				t.Fatalf("newURLPolicy(...): %v", err)
+			}
+			u, _ := url.Parse(tc.u)
+			if diff := cmp.Diff(tc.want, p.checkURL(u), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncheckURL(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestURLPolicyCheckIP(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      apisv1alpha1.URLPolicy
+		ip     string
+		want   error
+	}{
+		"NoRestrictions": {
+			reason: "Any address should be allowed by an empty policy.",
+			ip:     "169.254.169.254",
+		},
+		"MetadataEndpoint": {
+			reason: "Link-local addresses should be denied when private networks are denied.",
+			p:      apisv1alpha1.URLPolicy{DenyPrivateNetworks: true},
+			ip:     "169.254.169.254",
+			want:   errors.Errorf(errFmtAddressDenied, "169.254.169.254"),
+		},
+		"PrivateNetwork": {
+			reason: "Private addresses should be denied when private networks are denied.",
+			p:      apisv1alpha1.URLPolicy{DenyPrivateNetworks: true},
+			ip:     "10.96.0.1",
+			want:   errors.Errorf(errFmtAddressDenied, "10.96.0.1"),
+		},
+		"PublicAddress": {
+			reason: "Public addresses should be allowed when private networks are denied.",
+			p:      apisv1alpha1.URLPolicy{DenyPrivateNetworks: true},
+			ip:     "93.184.216.34",
+		},
+		"PrivateNetworkAllowed": {
+			reason: "Explicitly allowed ranges should be allowed even when private networks are denied.",
+			p:      apisv1alpha1.URLPolicy{DenyPrivateNetworks: true, AllowedCIDRs: []string{"10.0.0.0/24"}},
+			ip:     "10.0.0.10",
+		},
+		"NotAllowed": {
+			reason: "Addresses outside the allowed ranges should be denied.",
+			p:      apisv1alpha1.URLPolicy{AllowedCIDRs: []string{"10.0.0.0/24"}},
+			ip:     "93.184.216.34",
+			want:   errors.Errorf(errFmtAddressDenied, "93.184.216.34"),
+		},
+		"Denied": {
+			reason: "Denied ranges should be denied even if they are allowed.",
+			p:      apisv1alpha1.URLPolicy{AllowedCIDRs: []string{"10.0.0.0/8"}, DeniedCIDRs: []string{"10.0.0.0/24"}},
+			ip:     "10.0.0.10",
+			want:   errors.Errorf(errFmtAddressDenied, "10.0.0.10"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := newURLPolicy(&tc.p)
+			if err != nil {
+				t.Fatalf("newURLPolicy(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, p.checkIP(net.ParseIP(tc.ip)), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncheckIP(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestURLPolicyDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	// Requesting the server by hostname ensures the policy is enforced
+	// against the resolved address.
+	u := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	cases := map[string]struct {
+		reason  string
+		p       *apisv1alpha1.URLPolicy
+		wantErr bool
+	}{
+		"Denied": {
+			reason:  "Connections to resolved loopback addresses should be denied when private networks are denied.",
+			p:       &apisv1alpha1.URLPolicy{DenyPrivateNetworks: true},
+			wantErr: true,
+		},
+		"Allowed": {
+			reason: "Connections to explicitly allowed ranges should be allowed.",
+			p:      &apisv1alpha1.URLPolicy{DenyPrivateNetworks: true, AllowedCIDRs: []string{"127.0.0.0/8", "::1/128"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hc, err := newClientCache().Get("default", clientConfig{URLPolicy: tc.p})
+			if err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			r := request{Method: http.MethodGet, URL: u}
+			hc.configure(&r)
+
+			_, err = doRequest(context.Background(), r, validators{})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\ndoRequest(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errRefreshSchedule = "cannot parse refresh schedule"
+)
+
+// nextRefresh returns when data refreshed at the supplied time should next be
+// refreshed, or nil if neither a refresh schedule nor a refresh interval is
+// configured. A schedule takes precedence over an interval.
+func nextRefresh(p v1alpha1.DataSourceParameters, last time.Time) (*metav1.Time, error) {
+	switch {
+	case p.RefreshSchedule != nil:
+		s, err := cron.ParseStandard(*p.RefreshSchedule)
+		if err != nil {
+			return nil, errors.Wrap(err, errRefreshSchedule)
+		}
+		t := metav1.NewTime(s.Next(last))
+		return &t, nil
+	case p.RefreshInterval != nil:
+		t := metav1.NewTime(last.Add(p.RefreshInterval.Duration))
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// recordRefresh records that the data of the supplied DataSource was
+// successfully refreshed at the supplied time, and when it should next be
+// refreshed.
+func recordRefresh(cr *v1alpha1.DataSource, now time.Time) error {
+	if err := scheduleRefresh(cr, now); err != nil {
+		return err
+	}
+	cr.Status.LastSuccessfulRefresh = cr.Status.LastRefreshTime
+	if cr.Spec.ForProvider.Staleness != nil {
+		cr.SetConditions(v1alpha1.Refreshed())
+	}
+	return nil
+}
+
+// scheduleRefresh records that the data of the supplied DataSource was
+// refreshed at the supplied time, whether or not it was successfully looked
+// up, and when it should next be refreshed.
+func scheduleRefresh(cr *v1alpha1.DataSource, now time.Time) error {
+	next, err := nextRefresh(cr.Spec.ForProvider, now)
+	if err != nil {
+		return err
+	}
+	t := metav1.NewTime(now)
+	cr.Status.LastRefreshTime = &t
+	cr.Status.NextRefreshTime = next
+	return nil
+}
+
+// keepStale returns true if the data of the supplied DataSource should be
+// kept, despite the supplied error looking it up at the supplied time, as
+// configured by its staleness policy. Stale data is marked with a condition,
+// and is cleared once it expires if the policy requires it. Data that was
+// looked up for a previous generation of the DataSource is never kept.
+func keepStale(cr *v1alpha1.DataSource, now time.Time, err error) (bool, error) {
+	sp := cr.Spec.ForProvider.Staleness
+	if sp == nil || cr.Status.AtProvider == nil || cr.Status.ObservedGeneration != cr.GetGeneration() {
+		return false, nil
+	}
+
+	// Staleness is measured from the last successful refresh, which keeping
+	// stale data does not update. DataSources refreshed before it was
+	// recorded were last successfully refreshed at their last refresh time,
+	// which keeping stale data does update, so it is recorded before then.
+	if cr.Status.LastSuccessfulRefresh == nil {
+		cr.Status.LastSuccessfulRefresh = cr.Status.LastRefreshTime
+	}
+	last := cr.Status.LastSuccessfulRefresh
+	if sp.MaxStaleness != nil && last != nil && now.Sub(last.Time) > sp.MaxStaleness.Duration {
+		cr.SetConditions(v1alpha1.StaleExpired(err))
+		if sp.OnExpiry == v1alpha1.StaleExpiryClear {
+			cr.Status.AtProvider = nil
+		}
+		return false, nil
+	}
+
+	cr.SetConditions(v1alpha1.SourceUnavailable(err))
+	return true, scheduleRefresh(cr, now)
+}
+
+// fresh returns true if the data of the supplied DataSource need not be
+// refreshed at the supplied time. Data that depends on watched ConfigMaps and
+// Secrets, whether read from them or used to build a request, is never fresh,
+// because such DataSources are reconciled when the objects change.
+func fresh(cr *v1alpha1.DataSource, now time.Time) bool {
+	if cr.Status.AtProvider == nil || cr.Status.ObservedGeneration != cr.GetGeneration() {
+		return false
+	}
+	if cr.Status.NextRefreshTime == nil || !now.Before(cr.Status.NextRefreshTime.Time) {
+		return false
+	}
+
+	// Merged data depends on every source, while other data depends only on
+	// the source it was retrieved from.
+	p := cr.Spec.ForProvider
+	sources := sourcesOf(p)
+	if s := cr.Status.Source; p.Merge == nil && s != nil && s.Index < len(sources) {
+		sources = sources[s.Index : s.Index+1]
+	}
+	for _, src := range sources {
+		if rendered, err := renderSource(p, src); err == nil {
+			src = rendered
+		}
+		if len(sourceReferences(src)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// A refreshSchedule records the next refresh time of each DataSource as it is
+// observed by its ExternalClient, so that the refreshReconciler can requeue it
+// without reading it back from the API server.
+type refreshSchedule struct {
+	mu   sync.Mutex
+	next map[types.NamespacedName]time.Time
+}
+
+func newRefreshSchedule() *refreshSchedule {
+	return &refreshSchedule{next: map[types.NamespacedName]time.Time{}}
+}
+
+// record the next refresh time of the supplied DataSource, if any. Nothing is
+// recorded by a nil refreshSchedule.
+func (s *refreshSchedule) record(cr *v1alpha1.DataSource) {
+	if s == nil {
+		return
+	}
+	nn := types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cr.Status.NextRefreshTime == nil {
+		delete(s.next, nn)
+		return
+	}
+	s.next[nn] = cr.Status.NextRefreshTime.Time
+}
+
+// pop returns and forgets the recorded next refresh time of the named
+// DataSource.
+func (s *refreshSchedule) pop(nn types.NamespacedName) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.next[nn]
+	delete(s.next, nn)
+	return t, ok
+}
+
+// A refreshReconciler wraps a DataSource reconciler, requeueing each
+// DataSource at its next refresh time rather than after the poll interval.
+type refreshReconciler struct {
+	reconcile.Reconciler
+
+	// schedule is recorded by the ExternalClient of the wrapped reconciler.
+	schedule *refreshSchedule
+	now      func() time.Time
+}
+
+// Reconcile the DataSource, then requeue it at its next refresh time.
+func (r *refreshReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.Reconciler.Reconcile(ctx, req)
+	next, ok := r.schedule.pop(req.NamespacedName)
+
+	// Errors and explicit requeues are retried with backoff, and no
+	// requeue means the DataSource was deleted.
+	if err != nil || res.Requeue || res.RequeueAfter == 0 || !ok {
+		return res, err
+	}
+
+	// A next refresh time that has already passed falls back to the poll
+	// interval.
+	if after := next.Sub(r.now()); after > 0 {
+		res.RequeueAfter = after
+	}
+	return res, nil
+}
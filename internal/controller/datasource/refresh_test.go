@@ -0,0 +1,399 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestNextRefresh(t *testing.T) {
+	last := time.Date(2021, 7, 24, 14, 39, 19, 0, time.UTC)
+	at := func(t time.Time) *metav1.Time { mt := metav1.NewTime(t); return &mt }
+
+	type want struct {
+		next *metav1.Time
+		err  bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.DataSourceParameters
+		want   want
+	}{
+		"Unconfigured": {
+			reason: "No next refresh should be returned when no interval or schedule is configured.",
+		},
+		"Interval": {
+			reason: "The next refresh should be one interval after the last.",
+			p:      v1alpha1.DataSourceParameters{RefreshInterval: &metav1.Duration{Duration: 30 * time.Second}},
+			want:   want{next: at(last.Add(30 * time.Second))},
+		},
+		"Schedule": {
+			reason: "The next refresh should be the next time matching the schedule.",
+			p:      v1alpha1.DataSourceParameters{RefreshSchedule: pointer.StringPtr("0 6 * * *")},
+			want:   want{next: at(time.Date(2021, 7, 25, 6, 0, 0, 0, time.UTC))},
+		},
+		"SchedulePrecedence": {
+			reason: "A schedule should take precedence over an interval.",
+			p: v1alpha1.DataSourceParameters{
+				RefreshInterval: &metav1.Duration{Duration: 30 * time.Second},
+				RefreshSchedule: pointer.StringPtr("0 6 * * *"),
+			},
+			want: want{next: at(time.Date(2021, 7, 25, 6, 0, 0, 0, time.UTC))},
+		},
+		"InvalidSchedule": {
+			reason: "An invalid schedule should return an error.",
+			p:      v1alpha1.DataSourceParameters{RefreshSchedule: pointer.StringPtr("daily")},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			next, err := nextRefresh(tc.p, last)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nnextRefresh(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.next, next); diff != "" {
+				t.Errorf("\n%s\nnextRefresh(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFresh(t *testing.T) {
+	now := time.Now()
+	later := metav1.NewTime(now.Add(time.Minute))
+	earlier := metav1.NewTime(now.Add(-time.Minute))
+
+	ds := func(p v1alpha1.DataSourceParameters, next *metav1.Time) *v1alpha1.DataSource {
+		d := dataSource("ds", p)
+		d.Status.AtProvider = &runtime.RawExtension{Raw: []byte(`{}`)}
+		d.Status.NextRefreshTime = next
+		return &d
+	}
+	url := v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeURL, URL: pointer.StringPtr("https://example.org")}}
+	cm := v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{SourceType: v1alpha1.SourceTypeConfigMap, ConfigMapName: pointer.StringPtr("my-values")}}
+	fallback := v1alpha1.DataSourceParameters{Sources: []v1alpha1.SourceParameters{url.SourceParameters, cm.SourceParameters}}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.DataSource
+		want   bool
+	}{
+		"BeforeNextRefresh": {
+			reason: "Data should be fresh before its next refresh time.",
+			cr:     ds(url, &later),
+			want:   true,
+		},
+		"AfterNextRefresh": {
+			reason: "Data should not be fresh after its next refresh time.",
+			cr:     ds(url, &earlier),
+		},
+		"NoNextRefresh": {
+			reason: "Data should not be fresh when no refresh time is configured.",
+			cr:     ds(url, nil),
+		},
+		"Watched": {
+			reason: "Data retrieved from watched objects should never be fresh.",
+			cr:     ds(cm, &later),
+		},
+		"SpecChanged": {
+			reason: "Data should not be fresh if the spec has changed since it was retrieved.",
+			cr: func() *v1alpha1.DataSource {
+				d := ds(url, &later)
+				d.SetGeneration(2)
+				return d
+			}(),
+		},
+		"WatchedFallback": {
+			reason: "Data retrieved from a watched fallback source should never be fresh.",
+			cr: func() *v1alpha1.DataSource {
+				d := ds(fallback, &later)
+				d.Status.Source = &v1alpha1.SourceStatus{Index: 1, Type: v1alpha1.SourceTypeConfigMap}
+				return d
+			}(),
+		},
+		"UnwatchedFallback": {
+			reason: "Data retrieved from an unwatched source should be fresh, even if a later fallback source is watched.",
+			cr: func() *v1alpha1.DataSource {
+				d := ds(fallback, &later)
+				d.Status.Source = &v1alpha1.SourceStatus{Index: 0, Type: v1alpha1.SourceTypeURL}
+				return d
+			}(),
+			want: true,
+		},
+		"WatchedHeaders": {
+			reason: "Data retrieved using headers read from watched objects should never be fresh.",
+			cr: ds(v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{
+				SourceType: v1alpha1.SourceTypeURL,
+				URL:        pointer.StringPtr("https://example.org"),
+				Request: &v1alpha1.HTTPRequest{HeadersFrom: []v1alpha1.HeaderSource{{
+					Name:      "X-Tenant",
+					ValueFrom: v1alpha1.ValueSource{ConfigMapKeyRef: &v1alpha1.KeyReference{Name: "tenant", Key: "id"}},
+				}}},
+			}}, &later),
+		},
+		"WatchedKubernetesConfigMap": {
+			reason: "Data retrieved from a ConfigMap using the kubernetes source type should never be fresh.",
+			cr: ds(v1alpha1.DataSourceParameters{SourceParameters: v1alpha1.SourceParameters{
+				SourceType: v1alpha1.SourceTypeKubernetes,
+				Object:     &v1alpha1.KubernetesObject{APIVersion: "v1", Kind: "ConfigMap", Name: "my-values"},
+			}}, &later),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := fresh(tc.cr, now); got != tc.want {
+				t.Errorf("\n%s\nfresh(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRefreshReconcilerReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Now()
+	next := metav1.NewTime(now.Add(30 * time.Second))
+
+	type want struct {
+		res reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		inner  reconcile.Result
+		err    error
+		next   *metav1.Time
+		want   want
+	}{
+		"Error": {
+			reason: "Errors from the wrapped reconciler should be returned unchanged.",
+			err:    errBoom,
+			next:   &next,
+			want:   want{err: errBoom},
+		},
+		"Requeue": {
+			reason: "Explicit requeues should be returned unchanged.",
+			inner:  reconcile.Result{Requeue: true},
+			next:   &next,
+			want:   want{res: reconcile.Result{Requeue: true}},
+		},
+		"NextRefresh": {
+			reason: "DataSources should be requeued at their next refresh time.",
+			inner:  reconcile.Result{RequeueAfter: time.Minute},
+			next:   &next,
+			want:   want{res: reconcile.Result{RequeueAfter: 30 * time.Second}},
+		},
+		"NoNextRefresh": {
+			reason: "DataSources without a next refresh time should be requeued after the poll interval.",
+			inner:  reconcile.Result{RequeueAfter: time.Minute},
+			want:   want{res: reconcile.Result{RequeueAfter: time.Minute}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newRefreshSchedule()
+			r := &refreshReconciler{
+				Reconciler: reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+					ds := dataSource("ds", v1alpha1.DataSourceParameters{})
+					ds.Status.NextRefreshTime = tc.next
+					s.record(&ds)
+					return tc.inner, tc.err
+				}),
+				schedule: s,
+				now:      func() time.Time { return now },
+			}
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "ds"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res, res); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if _, ok := s.pop(types.NamespacedName{Name: "ds"}); ok {
+				t.Errorf("\n%s\nr.Reconcile(...): want the next refresh time to be forgotten", tc.reason)
+			}
+		})
+	}
+}
+
+func TestKeepStale(t *testing.T) {
+	now := time.Date(2021, 7, 24, 14, 39, 19, 0, time.UTC)
+	errBoom := errors.New("boom")
+	data := &runtime.RawExtension{Raw: []byte(`{"a":1}`)}
+
+	ds := func(sp *v1alpha1.StalenessPolicy, last time.Time) *v1alpha1.DataSource {
+		d := dataSource("ds", v1alpha1.DataSourceParameters{
+			SourceParameters: v1alpha1.SourceParameters{
+				SourceType: v1alpha1.SourceTypeURL,
+				URL:        pointer.StringPtr("https://example.org"),
+			},
+			RefreshInterval: &metav1.Duration{Duration: time.Minute},
+			Staleness:       sp,
+		})
+		d.Status.AtProvider = data
+		t := metav1.NewTime(last)
+		d.Status.LastSuccessfulRefresh = &t
+		return &d
+	}
+	window := &metav1.Duration{Duration: time.Hour}
+
+	type want struct {
+		kept      bool
+		reason    string
+		data      *runtime.RawExtension
+		refreshed bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.DataSource
+		want   want
+	}{
+		"NoPolicy": {
+			reason: "Data should not be kept without a staleness policy.",
+			cr:     ds(nil, now),
+			want:   want{data: data},
+		},
+		"NoData": {
+			reason: "There is nothing to keep if no data was ever looked up.",
+			cr: func() *v1alpha1.DataSource {
+				d := ds(&v1alpha1.StalenessPolicy{}, now)
+				d.Status.AtProvider = nil
+				return d
+			}(),
+		},
+		"SpecChanged": {
+			reason: "Data looked up for a previous generation should not be kept.",
+			cr: func() *v1alpha1.DataSource {
+				d := ds(&v1alpha1.StalenessPolicy{}, now)
+				d.SetGeneration(2)
+				return d
+			}(),
+			want: want{data: data},
+		},
+		"Unlimited": {
+			reason: "Data should be kept indefinitely without a max staleness, and its next refresh scheduled.",
+			cr:     ds(&v1alpha1.StalenessPolicy{}, now.Add(-24*time.Hour)),
+			want:   want{kept: true, reason: string(v1alpha1.ReasonSourceUnavailable), data: data, refreshed: true},
+		},
+		"WithinWindow": {
+			reason: "Data should be kept within its max staleness.",
+			cr:     ds(&v1alpha1.StalenessPolicy{MaxStaleness: window}, now.Add(-time.Minute)),
+			want:   want{kept: true, reason: string(v1alpha1.ReasonSourceUnavailable), data: data, refreshed: true},
+		},
+		"ExpiredFail": {
+			reason: "Expired data should not be kept, but should not be cleared by default.",
+			cr:     ds(&v1alpha1.StalenessPolicy{MaxStaleness: window, OnExpiry: v1alpha1.StaleExpiryFail}, now.Add(-2*time.Hour)),
+			want:   want{reason: string(v1alpha1.ReasonStaleExpired), data: data},
+		},
+		"ExpiredClear": {
+			reason: "Expired data should be cleared if the policy requires it.",
+			cr:     ds(&v1alpha1.StalenessPolicy{MaxStaleness: window, OnExpiry: v1alpha1.StaleExpiryClear}, now.Add(-2*time.Hour)),
+			want:   want{reason: string(v1alpha1.ReasonStaleExpired)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kept, err := keepStale(tc.cr, now, errBoom)
+			if err != nil {
+				t.Fatalf("\n%s\nkeepStale(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.kept, kept); diff != "" {
+				t.Errorf("\n%s\nkeepStale(...): -want kept, +got kept:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reason, string(tc.cr.GetCondition(v1alpha1.TypeStale).Reason)); diff != "" {
+				t.Errorf("\n%s\nkeepStale(...): -want condition reason, +got condition reason:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\nkeepStale(...): -want data, +got data:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.refreshed, tc.cr.Status.NextRefreshTime != nil); diff != "" {
+				t.Errorf("\n%s\nkeepStale(...): -want next refresh scheduled, +got next refresh scheduled:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKeepStaleRepeatedly(t *testing.T) {
+	start := time.Date(2021, 7, 24, 14, 39, 19, 0, time.UTC)
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		mod    func(d *v1alpha1.DataSource)
+	}{
+		"LastSuccessfulRefresh": {
+			reason: "Keeping stale data should not extend the time it may be kept for.",
+			mod: func(d *v1alpha1.DataSource) {
+				t := metav1.NewTime(start)
+				d.Status.LastSuccessfulRefresh = &t
+			},
+		},
+		"LastRefreshTime": {
+			reason: "Keeping stale data refreshed before the last successful refresh was recorded should not extend the time it may be kept for.",
+			mod: func(d *v1alpha1.DataSource) {
+				t := metav1.NewTime(start)
+				d.Status.LastRefreshTime = &t
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := dataSource("ds", v1alpha1.DataSourceParameters{
+				RefreshInterval: &metav1.Duration{Duration: time.Minute},
+				Staleness:       &v1alpha1.StalenessPolicy{MaxStaleness: &metav1.Duration{Duration: 5 * time.Minute}},
+			})
+			d.Status.AtProvider = &runtime.RawExtension{Raw: []byte(`{"a":1}`)}
+			tc.mod(&d)
+
+			// Fail once a minute, keeping the data for five minutes.
+			for i := 1; i <= 10; i++ {
+				kept, err := keepStale(&d, start.Add(time.Duration(i)*time.Minute), errBoom)
+				if err != nil {
+					t.Fatalf("\n%s\nkeepStale(...): %v", tc.reason, err)
+				}
+				if diff := cmp.Diff(i <= 5, kept); diff != "" {
+					t.Errorf("\n%s\nkeepStale(...): failure %d: -want kept, +got kept:\n%s\n", tc.reason, i, diff)
+				}
+			}
+			if diff := cmp.Diff(v1alpha1.ReasonStaleExpired, d.GetCondition(v1alpha1.TypeStale).Reason); diff != "" {
+				t.Errorf("\n%s\nkeepStale(...): -want condition reason, +got condition reason:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
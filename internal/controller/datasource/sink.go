@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errWriteSink       = "cannot write looked up data"
+	errGetSink         = "cannot get written data"
+	errDeleteSink      = "cannot delete written data"
+	errSinkNamespace   = "a ProviderConfig namespace is required to write looked up data"
+	errSinkObject      = "data must be an object to be flattened into keys"
+	errSinkControlled  = "existing object is not controlled by this DataSource"
+	errFmtSinkKind     = "unknown writeTo kind %s"
+	errFmtSinkFlatten  = "unknown writeTo flatten strategy %s"
+	errFmtSinkKey      = "invalid key %s: %s"
+	errFmtSinkConflict = "path %s is written to more than once"
+
+	defaultSinkKey       = "data.json"
+	defaultSinkSeparator = "."
+)
+
+// flatten returns the supplied data flattened into keys as configured by the
+// supplied sink. Values that are strings are returned as-is, and all other
+// values as JSON.
+func flatten(w v1alpha1.WriteTo, re *runtime.RawExtension) (map[string][]byte, error) {
+	f := v1alpha1.FlattenJSON
+	if w.Flatten != nil {
+		f = *w.Flatten
+	}
+
+	var data interface{}
+	if re != nil && len(re.Raw) > 0 {
+		if err := json.Unmarshal(re.Raw, &data); err != nil {
+			return nil, err
+		}
+	}
+
+	out := map[string][]byte{}
+	switch f {
+	case v1alpha1.FlattenJSON:
+		k := defaultSinkKey
+		if w.Key != nil {
+			k = *w.Key
+		}
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = b
+	case v1alpha1.FlattenKeys:
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, errors.New(errSinkObject)
+		}
+		for k, v := range obj {
+			b, err := sinkValue(v)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = b
+		}
+	case v1alpha1.FlattenPaths:
+		if _, ok := data.(map[string]interface{}); !ok {
+			return nil, errors.New(errSinkObject)
+		}
+		sep := defaultSinkSeparator
+		if w.Separator != nil {
+			sep = *w.Separator
+		}
+		if err := flattenPaths(out, nil, sep, data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf(errFmtSinkFlatten, f)
+	}
+
+	for k := range out {
+		if errs := validation.IsConfigMapKey(k); len(errs) > 0 {
+			return nil, errors.Errorf(errFmtSinkKey, k, strings.Join(errs, ", "))
+		}
+	}
+	return out, nil
+}
+
+// flattenPaths writes each value within the supplied data that is not a
+// non-empty object or array to the supplied map, keyed by its path.
+func flattenPaths(out map[string][]byte, p []string, sep string, data interface{}) error {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		if len(d) > 0 {
+			for k, v := range d {
+				if err := flattenPaths(out, append(p[:len(p):len(p)], k), sep, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if len(d) > 0 {
+			for i, v := range d {
+				if err := flattenPaths(out, append(p[:len(p):len(p)], strconv.Itoa(i)), sep, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	k := strings.Join(p, sep)
+	if _, ok := out[k]; ok {
+		return errors.Errorf(errFmtSinkConflict, k)
+	}
+	b, err := sinkValue(data)
+	if err != nil {
+		return err
+	}
+	out[k] = b
+	return nil
+}
+
+// sinkValue returns the supplied value as-is if it is a string, and as JSON
+// otherwise.
+func sinkValue(v interface{}) ([]byte, error) {
+	if s, ok := v.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(v)
+}
+
+// sinkObject returns the object the supplied DataSource writes its data to,
+// without any data.
+func sinkObject(cr *v1alpha1.DataSource, namespace string) (client.Object, error) {
+	w := cr.Spec.WriteTo
+	om := metav1.ObjectMeta{Name: w.Name, Namespace: namespace}
+	switch w.Kind {
+	case v1alpha1.SinkKindConfigMap, "":
+		return &apiv1.ConfigMap{ObjectMeta: om}, nil
+	case v1alpha1.SinkKindSecret:
+		return &apiv1.Secret{ObjectMeta: om, Type: apiv1.SecretTypeOpaque}, nil
+	default:
+		return nil, errors.Errorf(errFmtSinkKind, w.Kind)
+	}
+}
+
+// writeSink writes the data of the supplied DataSource to the ConfigMap or
+// Secret it is configured to write to, if any. The object is created if it
+// does not exist, and replaced only if it is controlled by the DataSource and
+// its data changed. Existing objects without a controller are never taken
+// over.
+func writeSink(ctx context.Context, kube client.Client, namespace string, cr *v1alpha1.DataSource) error {
+	if cr.Spec.WriteTo == nil {
+		return nil
+	}
+	if namespace == "" {
+		return errors.New(errSinkNamespace)
+	}
+
+	data, err := flatten(*cr.Spec.WriteTo, cr.Status.AtProvider)
+	if err != nil {
+		return errors.Wrap(err, errWriteSink)
+	}
+	o, err := sinkObject(cr, namespace)
+	if err != nil {
+		return errors.Wrap(err, errWriteSink)
+	}
+
+	switch s := o.(type) {
+	case *apiv1.ConfigMap:
+		s.Data = make(map[string]string, len(data))
+		for k, v := range data {
+			s.Data[k] = string(v)
+		}
+	case *apiv1.Secret:
+		s.Data = data
+	}
+	meta.AddOwnerReference(o, meta.AsController(meta.TypedReferenceTo(cr, v1alpha1.DataSourceGroupVersionKind)))
+
+	// Data is written on every reconcile, so we avoid updating an object
+	// that already holds it.
+	current, err := getSink(ctx, kube, namespace, cr)
+	if err != nil {
+		return errors.Wrap(err, errWriteSink)
+	}
+	if current != nil && sinkUpToDate(current, o) {
+		return nil
+	}
+
+	return errors.Wrap(resource.NewAPIUpdatingApplicator(kube).Apply(ctx, o, mustBeControlledBy(cr)), errWriteSink)
+}
+
+// sinkUpToDate returns true if the supplied existing object already holds the
+// data of the supplied desired object.
+func sinkUpToDate(current, desired client.Object) bool {
+	switch d := desired.(type) {
+	case *apiv1.ConfigMap:
+		c, ok := current.(*apiv1.ConfigMap)
+		return ok && cmp.Equal(c.Data, d.Data, cmpopts.EquateEmpty())
+	case *apiv1.Secret:
+		c, ok := current.(*apiv1.Secret)
+		return ok && c.Type == d.Type && cmp.Equal(c.Data, d.Data, cmpopts.EquateEmpty())
+	}
+	return false
+}
+
+// mustBeControlledBy returns an ApplyOption that refuses to update any
+// existing object that is not controlled by the supplied DataSource.
+// Unlike resource.MustBeControllableBy objects without a controller are
+// refused too, as they may be a Secret or ConfigMap that something else relies
+// on.
+func mustBeControlledBy(cr *v1alpha1.DataSource) resource.ApplyOption {
+	return func(_ context.Context, current, _ runtime.Object) error {
+		if !metav1.IsControlledBy(current.(metav1.Object), cr) {
+			return errors.New(errSinkControlled)
+		}
+		return nil
+	}
+}
+
+// getSink returns the ConfigMap or Secret the supplied DataSource writes its
+// data to, or nil if it does not exist or is not controlled by the
+// DataSource.
+func getSink(ctx context.Context, kube client.Client, namespace string, cr *v1alpha1.DataSource) (client.Object, error) {
+	if cr.Spec.WriteTo == nil || namespace == "" {
+		return nil, nil
+	}
+	o, err := sinkObject(cr, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := kube.Get(ctx, types.NamespacedName{Name: o.GetName(), Namespace: namespace}, o); err != nil {
+		return nil, resource.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(o, cr) {
+		return nil, nil
+	}
+	return o, nil
+}
+
+// deleteSink deletes the ConfigMap or Secret the supplied DataSource writes
+// its data to, if any. Objects that are not controlled by the DataSource are
+// left alone.
+func deleteSink(ctx context.Context, kube client.Client, namespace string, cr *v1alpha1.DataSource) error {
+	o, err := getSink(ctx, kube, namespace, cr)
+	if err != nil || o == nil {
+		return errors.Wrap(err, errDeleteSink)
+	}
+	return errors.Wrap(resource.IgnoreNotFound(kube.Delete(ctx, o)), errDeleteSink)
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestFlatten(t *testing.T) {
+	data := `{"db":{"hosts":["a","b"],"port":5432},"name":"payments","empty":{}}`
+	key := "config.json"
+	sep := "_"
+
+	flat := func(f v1alpha1.Flatten) *v1alpha1.Flatten { return &f }
+
+	type want struct {
+		data map[string][]byte
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		w      v1alpha1.WriteTo
+		data   string
+		want   want
+	}{
+		"Default": {
+			reason: "The whole document should be written as JSON to the default key.",
+			data:   `{"name":"payments"}`,
+			want: want{
+				data: map[string][]byte{"data.json": []byte(`{"name":"payments"}`)},
+			},
+		},
+		"JSONKey": {
+			reason: "The whole document should be written as JSON to the configured key.",
+			w:      v1alpha1.WriteTo{Flatten: flat(v1alpha1.FlattenJSON), Key: &key},
+			data:   `["a"]`,
+			want: want{
+				data: map[string][]byte{"config.json": []byte(`["a"]`)},
+			},
+		},
+		"Keys": {
+			reason: "Each top-level key should be written, with values that are not strings as JSON.",
+			w:      v1alpha1.WriteTo{Flatten: flat(v1alpha1.FlattenKeys)},
+			data:   data,
+			want: want{
+				data: map[string][]byte{
+					"db":    []byte(`{"hosts":["a","b"],"port":5432}`),
+					"name":  []byte("payments"),
+					"empty": []byte(`{}`),
+				},
+			},
+		},
+		"KeysNotObject": {
+			reason: "Documents that are not objects cannot be flattened into keys.",
+			w:      v1alpha1.WriteTo{Flatten: flat(v1alpha1.FlattenKeys)},
+			data:   `["a"]`,
+			want: want{
+				err: errors.New(errSinkObject),
+			},
+		},
+		"Paths": {
+			reason: "Each value should be written to a key named after its path.",
+			w:      v1alpha1.WriteTo{Flatten: flat(v1alpha1.FlattenPaths), Separator: &sep},
+			data:   data,
+			want: want{
+				data: map[string][]byte{
+					"db_hosts_0": []byte("a"),
+					"db_hosts_1": []byte("b"),
+					"db_port":    []byte("5432"),
+					"name":       []byte("payments"),
+					"empty":      []byte(`{}`),
+				},
+			},
+		},
+		"PathsConflict": {
+			reason: "Values whose paths collide should return an error.",
+			w:      v1alpha1.WriteTo{Flatten: flat(v1alpha1.FlattenPaths)},
+			data:   `{"a":{"b":"1"},"a.b":"2"}`,
+			want: want{
+				err: errors.Errorf(errFmtSinkConflict, "a.b"),
+			},
+		},
+		"InvalidKey": {
+			reason: "Keys that are not valid ConfigMap keys should return an error.",
+			w:      v1alpha1.WriteTo{Flatten: flat(v1alpha1.FlattenKeys)},
+			data:   `{"a b":"1"}`,
+			want: want{
+				err: errors.Errorf(errFmtSinkKey, "a b", "a valid config key must consist of alphanumeric characters, '-', '_' or '.' (e.g. 'key.name',  or 'KEY_NAME',  or 'key-name', regex used for validation is '[-._a-zA-Z0-9]+')"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := flatten(tc.w, &runtime.RawExtension{Raw: []byte(tc.data)})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nflatten(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("\n%s\nflatten(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWriteSink(t *testing.T) {
+	errBoom := errors.New("boom")
+	errNotFound := kerrors.NewNotFound(schema.GroupResource{}, "")
+
+	cr := &v1alpha1.DataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "ds", UID: "ds-uid"},
+		Spec: v1alpha1.DataSourceSpec{
+			WriteTo: &v1alpha1.WriteTo{Kind: v1alpha1.SinkKindSecret, Name: "sink"},
+		},
+		Status: v1alpha1.DataSourceStatus{
+			AtProvider: &runtime.RawExtension{Raw: []byte(`{"a":1}`)},
+		},
+	}
+
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "sink",
+			Namespace:       "test",
+			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(cr, v1alpha1.DataSourceGroupVersionKind))},
+		},
+		Type: apiv1.SecretTypeOpaque,
+		Data: map[string][]byte{"data.json": []byte(`{"a":1}`)},
+	}
+
+	type args struct {
+		kube      client.Client
+		namespace string
+		cr        *v1alpha1.DataSource
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"NoSink": {
+			reason: "Nothing should be written if no sink is configured.",
+			args: args{
+				cr: &v1alpha1.DataSource{},
+			},
+		},
+		"NoNamespace": {
+			reason: "A ProviderConfig namespace is required to write data.",
+			args: args{
+				cr: cr,
+			},
+			want: errors.New(errSinkNamespace),
+		},
+		"Create": {
+			reason: "The sink should be created, controlled by the DataSource, if it does not exist.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errNotFound),
+					MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+						if diff := cmp.Diff(secret, obj); diff != "" {
+							t.Errorf("Create(...): -want, +got:\n%s\n", diff)
+						}
+						return nil
+					},
+				},
+				namespace: "test",
+				cr:        cr,
+			},
+		},
+		"NotControllable": {
+			reason: "Objects controlled by something else should not be written to.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(&xpv1.TypedReference{UID: "other"})})
+						return nil
+					}),
+				},
+				namespace: "test",
+				cr:        cr,
+			},
+			want: errors.Wrap(errors.New(errSinkControlled), errWriteSink),
+		},
+		"NoController": {
+			reason: "Existing objects without a controller should not be taken over.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.SetOwnerReferences(nil)
+						return nil
+					}),
+				},
+				namespace: "test",
+				cr:        cr,
+			},
+			want: errors.Wrap(errors.New(errSinkControlled), errWriteSink),
+		},
+		"Unchanged": {
+			reason: "Sinks that already hold the data should not be updated.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						secret.DeepCopyInto(obj.(*apiv1.Secret))
+						return nil
+					}),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+				namespace: "test",
+				cr:        cr,
+			},
+		},
+		"UpdateError": {
+			reason: "Errors updating the sink should be returned.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet:    test.NewMockGetFn(nil),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+				namespace: "test",
+				cr:        cr,
+			},
+			want: errors.Wrap(errors.Wrap(errBoom, "cannot update object"), errWriteSink),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := writeSink(context.Background(), tc.args.kube, tc.args.namespace, tc.args.cr)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nwriteSink(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteSink(t *testing.T) {
+	cr := &v1alpha1.DataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "ds", UID: "ds-uid"},
+		Spec: v1alpha1.DataSourceSpec{
+			WriteTo: &v1alpha1.WriteTo{Name: "sink"},
+		},
+	}
+
+	controller := true
+	controlledBy := func(uid string) func(client.Object) error {
+		return func(obj client.Object) error {
+			obj.SetOwnerReferences([]metav1.OwnerReference{{UID: ktypes.UID(uid), Controller: &controller}})
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason  string
+		get     func(client.Object) error
+		deleted bool
+	}{
+		"Controlled": {
+			reason:  "Sinks controlled by the DataSource should be deleted.",
+			get:     controlledBy("ds-uid"),
+			deleted: true,
+		},
+		"NotControlled": {
+			reason:  "Sinks controlled by something else should not be deleted.",
+			get:     controlledBy("other"),
+			deleted: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, tc.get),
+				MockDelete: func(_ context.Context, _ client.Object, _ ...client.DeleteOption) error {
+					deleted = true
+					return nil
+				},
+			}
+			if err := deleteSink(context.Background(), kube, "test", cr); err != nil {
+				t.Errorf("\n%s\ndeleteSink(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\ndeleteSink(...): -want deleted, +got deleted:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errFmtUnresolvedVariable = "variable %s is not resolved"
+	errFmtRenderTemplate     = "cannot render %s"
+)
+
+// variablesOf returns the resolved values of the variables of the supplied
+// parameters, keyed by name.
+func variablesOf(p v1alpha1.DataSourceParameters) (map[string]string, error) {
+	vars := make(map[string]string, len(p.Variables))
+	for _, v := range p.Variables {
+		if v.Value == nil {
+			return nil, errors.Errorf(errFmtUnresolvedVariable, v.Name)
+		}
+		vars[v.Name] = *v.Value
+	}
+	return vars, nil
+}
+
+// renderSource returns the supplied source with its url, request headers and
+// ConfigMap name rendered as templates using the variables of the supplied
+// parameters. Sources are returned as-is if there are no variables.
+func renderSource(p v1alpha1.DataSourceParameters, src v1alpha1.SourceParameters) (v1alpha1.SourceParameters, error) {
+	if len(p.Variables) == 0 {
+		return src, nil
+	}
+	vars, err := variablesOf(p)
+	if err != nil {
+		return src, err
+	}
+
+	out := *src.DeepCopy()
+	if out.URL != nil {
+		u, err := render("url", *out.URL, vars)
+		if err != nil {
+			return src, err
+		}
+		out.URL = &u
+	}
+	if out.ConfigMapName != nil {
+		n, err := render("configMapName", *out.ConfigMapName, vars)
+		if err != nil {
+			return src, err
+		}
+		out.ConfigMapName = &n
+	}
+	if out.Request != nil {
+		for k, v := range out.Request.Headers {
+			h, err := render("header "+k, v, vars)
+			if err != nil {
+				return src, err
+			}
+			out.Request.Headers[k] = h
+		}
+	}
+	return out, nil
+}
+
+// render the supplied template text using the supplied variables. Templates
+// that use undefined variables cannot be rendered.
+func render(name, text string, vars map[string]string) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, errFmtRenderTemplate, name)
+	}
+	b := &strings.Builder{}
+	if err := t.Execute(b, vars); err != nil {
+		return "", errors.Wrapf(err, errFmtRenderTemplate, name)
+	}
+	return b.String(), nil
+}
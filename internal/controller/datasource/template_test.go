@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestRenderSource(t *testing.T) {
+	region := v1alpha1.Variable{Name: "region", FieldPath: "region", Value: pointer.StringPtr("eu west")}
+	tenant := v1alpha1.Variable{Name: "tenant", FieldPath: "tenant", Value: pointer.StringPtr("payments")}
+
+	src := v1alpha1.SourceParameters{
+		SourceType:    v1alpha1.SourceTypeURL,
+		URL:           pointer.StringPtr("https://api.example.org/{{ .region | urlquery }}/config"),
+		ConfigMapName: pointer.StringPtr("{{ .tenant }}-defaults"),
+		Request: &v1alpha1.HTTPRequest{
+			Headers: map[string]string{"X-Tenant-ID": "{{ .tenant }}"},
+		},
+	}
+
+	type want struct {
+		src v1alpha1.SourceParameters
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.DataSourceParameters
+		want   want
+	}{
+		"NoVariables": {
+			reason: "Sources should be returned as-is if there are no variables.",
+			want:   want{src: src},
+		},
+		"Rendered": {
+			reason: "The url, ConfigMap name and request headers should be rendered using variables.",
+			p:      v1alpha1.DataSourceParameters{Variables: []v1alpha1.Variable{region, tenant}},
+			want: want{
+				src: v1alpha1.SourceParameters{
+					SourceType:    v1alpha1.SourceTypeURL,
+					URL:           pointer.StringPtr("https://api.example.org/eu+west/config"),
+					ConfigMapName: pointer.StringPtr("payments-defaults"),
+					Request: &v1alpha1.HTTPRequest{
+						Headers: map[string]string{"X-Tenant-ID": "payments"},
+					},
+				},
+			},
+		},
+		"Unresolved": {
+			reason: "Variables that have not been resolved should return an error.",
+			p:      v1alpha1.DataSourceParameters{Variables: []v1alpha1.Variable{{Name: "region"}}},
+			want: want{
+				src: src,
+				err: errors.Errorf(errFmtUnresolvedVariable, "region"),
+			},
+		},
+		"Undefined": {
+			reason: "Templates using undefined variables should return an error.",
+			p:      v1alpha1.DataSourceParameters{Variables: []v1alpha1.Variable{region}},
+			want: want{
+				src: src,
+				err: errors.Wrapf(errors.New(`template: configMapName:1:3: executing "configMapName" at <.tenant>: map has no entry for key "tenant"`), errFmtRenderTemplate, "configMapName"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := renderSource(tc.p, src)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nrenderSource(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.src, got); diff != "" {
+				t.Errorf("\n%s\nrenderSource(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+const (
+	errGetCABundle   = "cannot get CA bundle"
+	errGetClientCert = "cannot get client certificate"
+	errGetClientKey  = "cannot get client key"
+	errCABundle      = "CA bundle does not contain any PEM encoded certificates"
+	errClientCertKey = "clientCertificate and clientKeySecretRef must be specified together"
+	errClientKeyPair = "cannot load client certificate and key"
+	errSPKIPin       = "server certificate does not match any SPKI pin"
+)
+
+// resolveKey reads the value selected by the supplied KeySource, or returns
+// an empty string if it is nil.
+func resolveKey(ctx context.Context, kube client.Client, ks *apisv1alpha1.KeySource) (string, error) {
+	if ks == nil {
+		return "", nil
+	}
+	var ns string
+	vs := v1alpha1.ValueSource{}
+	if ref := ks.ConfigMapKeyRef; ref != nil {
+		ns = ref.Namespace
+		vs.ConfigMapKeyRef = &v1alpha1.KeyReference{Name: ref.Name, Key: ref.Key}
+	}
+	if ref := ks.SecretKeyRef; ref != nil {
+		ns = ref.Namespace
+		vs.SecretKeyRef = &v1alpha1.KeyReference{Name: ref.Name, Key: ref.Key}
+	}
+	return resolveValue(ctx, kube, ns, vs)
+}
+
+// tlsConfig returns the TLS configuration described by the supplied
+// clientConfig, or nil if the default configuration should be used.
+func tlsConfig(m clientConfig) (*tls.Config, error) {
+	if m.CABundle == "" && m.ClientCert == "" && m.ClientKey == "" && len(m.SPKIPins) == 0 {
+		return nil, nil
+	}
+
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if m.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(m.CABundle)) {
+			return nil, errors.New(errCABundle)
+		}
+		tc.RootCAs = pool
+	}
+
+	if (m.ClientCert == "") != (m.ClientKey == "") {
+		return nil, errors.New(errClientCertKey)
+	}
+	if m.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(m.ClientCert), []byte(m.ClientKey))
+		if err != nil {
+			return nil, errors.Wrap(err, errClientKeyPair)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(m.SPKIPins) > 0 {
+		tc.VerifyConnection = verifySPKIPins(m.SPKIPins)
+	}
+
+	return tc, nil
+}
+
+// verifySPKIPins returns a function that verifies that a connection's peer
+// presented a certificate whose public key matches one of the supplied pins.
+// It runs after the certificate chain has been verified as usual.
+func verifySPKIPins(pins []string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		for _, cert := range cs.PeerCertificates {
+			h := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			spki := base64.StdEncoding.EncodeToString(h[:])
+			for _, p := range pins {
+				if p == spki {
+					return nil
+				}
+			}
+		}
+		return errors.New(errSPKIPin)
+	}
+}
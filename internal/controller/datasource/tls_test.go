@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestTLSConfig(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		m      clientConfig
+		err    error
+	}{
+		"Default": {
+			reason: "No TLS configuration should be returned if no TLS material is supplied.",
+		},
+		"InvalidCABundle": {
+			reason: "A CA bundle without any certificates should be rejected.",
+			m:      clientConfig{CABundle: "not a certificate"},
+			err:    errors.New(errCABundle),
+		},
+		"ClientCertWithoutKey": {
+			reason: "A client certificate without a key should be rejected.",
+			m:      clientConfig{ClientCert: "cert"},
+			err:    errors.New(errClientCertKey),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tlsConfig(tc.m)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ntlsConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if got != nil {
+				t.Errorf("\n%s\ntlsConfig(...): want no TLS configuration", tc.reason)
+			}
+		})
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"secure":true}`))
+	}))
+	defer srv.Close()
+
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	h := sha256.Sum256(srv.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(h[:])
+
+	cases := map[string]struct {
+		reason  string
+		m       clientConfig
+		wantErr bool
+	}{
+		"UntrustedCA": {
+			reason:  "Servers whose certificates are issued by an untrusted CA should be rejected.",
+			wantErr: true,
+		},
+		"TrustedCA": {
+			reason: "Servers whose certificates are issued by a CA in the CA bundle should be trusted.",
+			m:      clientConfig{CABundle: ca},
+		},
+		"PinMatches": {
+			reason: "Servers presenting a certificate matching an SPKI pin should be trusted.",
+			m:      clientConfig{CABundle: ca, SPKIPins: []string{"bm9wZQ==", pin}},
+		},
+		"PinMismatch": {
+			reason:  "Servers presenting a certificate matching no SPKI pin should be rejected.",
+			m:       clientConfig{CABundle: ca, SPKIPins: []string{"bm9wZQ=="}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hc, err := newClientCache().Get("default", tc.m)
+			if err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			r := request{Method: http.MethodGet, URL: srv.URL}
+			hc.configure(&r)
+
+			_, err = doRequest(context.Background(), r, validators{})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\ndoRequest(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	c := newClientCache()
+
+	hc, _ := c.Get("default", clientConfig{})
+	if again, _ := c.Get("default", clientConfig{}); again != hc {
+		t.Errorf("Get(...): want the client of an unchanged ProviderConfig to be reused")
+	}
+	if changed, _ := c.Get("default", clientConfig{SPKIPins: []string{"bm9wZQ=="}}); changed == hc {
+		t.Errorf("Get(...): want a new client when the TLS configuration changes")
+	}
+}
+
+func TestHTTPClientCookies(t *testing.T) {
+	var cookies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "a"})
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	hc, err := newClientCache().Get("default", clientConfig{})
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		r := request{Method: http.MethodGet, URL: srv.URL}
+		hc.configure(&r)
+		if _, err := doRequest(context.Background(), r, validators{}); err != nil {
+			t.Fatalf("doRequest(...): %v", err)
+		}
+	}
+	if diff := cmp.Diff([]string{"", ""}, cookies); diff != "" {
+		t.Errorf("doRequest(...): want cookies set by one request not to be sent with the next: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	errCompileTransform    = "cannot compile transform"
+	errTransformData       = "cannot transform data"
+	errTransformNoOutput   = "transform produced no output"
+	errTransformProcess    = "cannot run transform process"
+	errFmtTransformTimeout = "transform did not complete within %s"
+	errFmtTransformMemory  = "transform exceeded its memory limit of %d bytes"
+	errFmtTransformInput   = "data of %d bytes exceeds the transform memory limit of %d bytes"
+)
+
+// TransformCommand is the command of the provider binary that evaluates a
+// single transform, read from stdin, in its own process.
+const TransformCommand = "transform"
+
+// Exit codes of the transform process.
+const (
+	transformExitError  = 1
+	transformExitMemory = 3
+)
+
+// memoryCheckInterval is how often the memory used by the transform process
+// is checked against its limit.
+const memoryCheckInterval = 10 * time.Millisecond
+
+// transformLimits bound the resources used to evaluate a transform.
+type transformLimits struct {
+	// timeout after which evaluation is cancelled.
+	timeout time.Duration
+
+	// memory is the number of bytes of heap that evaluation may use.
+	// Transforms are evaluated in their own process when memory is
+	// limited, so that the memory used by one evaluation can be measured,
+	// and an evaluation that exceeds it cannot affect the provider. Memory
+	// is not limited if it is zero.
+	memory uint64
+}
+
+// A transformer applies a compiled jq program to looked up data.
+type transformer struct {
+	src    string
+	code   *gojq.Code
+	limits transformLimits
+
+	// command returns the command that evaluates the transform in its own
+	// process.
+	command func(ctx context.Context) (*exec.Cmd, error)
+}
+
+// compileTransform compiles the supplied jq program.
+func compileTransform(src string, l transformLimits) (*transformer, error) {
+	q, err := gojq.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, err
+	}
+	return &transformer{src: src, code: code, limits: l, command: transformCommand}, nil
+}
+
+// transformCommand returns the TransformCommand of the running binary.
+func transformCommand(ctx context.Context) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.CommandContext(ctx, exe, TransformCommand), nil
+}
+
+// apply replaces the data in re with the output of the transform. A single
+// output is stored as-is, while several outputs are stored as an array.
+func (t *transformer) apply(ctx context.Context, re *runtime.RawExtension) error {
+	var outs []json.RawMessage
+	var err error
+	if t.limits.memory > 0 {
+		outs, err = t.runProcess(ctx, re.Raw)
+	} else {
+		var data interface{}
+		if err := json.Unmarshal(re.Raw, &data); err != nil {
+			return errors.Wrap(err, errTransformData)
+		}
+		outs, err = t.run(ctx, data)
+	}
+	if err != nil {
+		return errors.Wrap(err, errTransformData)
+	}
+
+	switch len(outs) {
+	case 0:
+		return errors.New(errTransformNoOutput)
+	case 1:
+		return re.UnmarshalJSON(outs[0])
+	}
+	b, err := json.Marshal(outs)
+	if err != nil {
+		return errors.Wrap(err, errTransformData)
+	}
+	return re.UnmarshalJSON(b)
+}
+
+// run evaluates the transform in the current process, returning its JSON
+// encoded outputs. Evaluation is cancelled if it exceeds its timeout.
+func (t *transformer) run(ctx context.Context, data interface{}) ([]json.RawMessage, error) {
+	if t.limits.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.limits.timeout)
+		defer cancel()
+	}
+
+	outs := []json.RawMessage{}
+	iter := t.code.RunWithContext(ctx, data)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			return outs, nil
+		}
+		if err, ok := v.(error); ok {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, errors.Errorf(errFmtTransformTimeout, t.limits.timeout)
+			}
+			return nil, err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		outs = append(outs, b)
+	}
+}
+
+// transformInput is written to the stdin of the transform process.
+type transformInput struct {
+	Transform string          `json:"transform"`
+	Data      json.RawMessage `json:"data"`
+	Memory    uint64          `json:"memory"`
+}
+
+// runProcess evaluates the transform in its own process, returning its JSON
+// encoded outputs. Data larger than the memory limit is refused without
+// being evaluated, and the process is killed if it exceeds its timeout.
+func (t *transformer) runProcess(ctx context.Context, data []byte) ([]json.RawMessage, error) {
+	if uint64(len(data)) > t.limits.memory {
+		return nil, errors.Errorf(errFmtTransformInput, len(data), t.limits.memory)
+	}
+	if t.limits.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.limits.timeout)
+		defer cancel()
+	}
+
+	in, err := json.Marshal(transformInput{Transform: t.src, Data: data, Memory: t.limits.memory})
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := t.command(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errTransformProcess)
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(in), stdout, stderr
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, errors.Errorf(errFmtTransformTimeout, t.limits.timeout)
+	}
+	var ee *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &ee) && ee.ExitCode() == transformExitMemory:
+		return nil, errors.Errorf(errFmtTransformMemory, t.limits.memory)
+	case errors.As(err, &ee) && ee.ExitCode() == transformExitError:
+		return nil, errors.New(strings.TrimSpace(stderr.String()))
+	default:
+		return nil, errors.Wrap(err, errTransformProcess)
+	}
+
+	outs := []json.RawMessage{}
+	return outs, errors.Wrap(json.Unmarshal(stdout.Bytes(), &outs), errTransformProcess)
+}
+
+// RunTransform evaluates a transform read from stdin, writing its JSON encoded
+// outputs to stdout as an array, and returns the exit code of the process. It
+// is the TransformCommand of the provider binary. The process exits as soon as
+// its heap exceeds the memory limit it was given, which measures only this
+// evaluation because the process evaluates nothing else.
+func RunTransform(stdin io.Reader, stdout, stderr io.Writer) int {
+	in := transformInput{}
+	if err := json.NewDecoder(stdin).Decode(&in); err != nil {
+		fmt.Fprintln(stderr, err)
+		return transformExitError
+	}
+	if in.Memory > 0 {
+		go watchMemory(in.Memory, func() { os.Exit(transformExitMemory) })
+	}
+
+	t, err := compileTransform(in.Transform, transformLimits{})
+	if err != nil {
+		fmt.Fprintln(stderr, errors.Wrap(err, errCompileTransform))
+		return transformExitError
+	}
+	var data interface{}
+	if err := json.Unmarshal(in.Data, &data); err != nil {
+		fmt.Fprintln(stderr, err)
+		return transformExitError
+	}
+	in.Data = nil
+
+	outs, err := t.run(context.Background(), data)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return transformExitError
+	}
+	if err := json.NewEncoder(stdout).Encode(outs); err != nil {
+		fmt.Fprintln(stderr, err)
+		return transformExitError
+	}
+	return 0
+}
+
+// watchMemory calls exceeded once the heap of the current process is larger
+// than the supplied number of bytes.
+func watchMemory(limit uint64, exceeded func()) {
+	ms := &goruntime.MemStats{}
+	t := time.NewTicker(memoryCheckInterval)
+	defer t.Stop()
+	for range t.C {
+		goruntime.ReadMemStats(ms)
+		if ms.HeapAlloc > limit {
+			exceeded()
+			return
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// TestMain runs RunTransform when the test binary is run as the
+// TransformCommand, as the provider binary is.
+func TestMain(m *testing.M) {
+	if len(os.Args) == 2 && os.Args[1] == TransformCommand {
+		os.Exit(RunTransform(os.Stdin, os.Stdout, os.Stderr))
+	}
+	os.Exit(m.Run())
+}
+
+func TestTransform(t *testing.T) {
+	data := `{"clusters":[{"id":"a","size":3},{"id":"b","size":5}]}`
+
+	type want struct {
+		data string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		src    string
+		limits transformLimits
+		want   want
+	}{
+		"Reshape": {
+			reason: "The output of the transform should be stored.",
+			src:    `.clusters | map({key: .id, value: .size}) | from_entries`,
+			want:   want{data: `{"a":3,"b":5}`},
+		},
+		"SeveralOutputs": {
+			reason: "Several outputs should be stored as an array.",
+			src:    `.clusters[] | select(.size > 1) | .id`,
+			want:   want{data: `["a","b"]`},
+		},
+		"NoOutput": {
+			reason: "Transforms that produce no output should return an error.",
+			src:    `empty`,
+			want:   want{data: data, err: errors.New(errTransformNoOutput)},
+		},
+		"Timeout": {
+			reason: "Transforms that exceed their timeout should be cancelled.",
+			src:    `last(range(1e12))`,
+			limits: transformLimits{timeout: 50 * time.Millisecond},
+			want: want{
+				data: data,
+				err:  errors.Wrap(errors.Errorf(errFmtTransformTimeout, 50*time.Millisecond), errTransformData),
+			},
+		},
+		"Process": {
+			reason: "Transforms with a memory limit should be evaluated in their own process.",
+			src:    `.clusters[] | select(.size > 1) | .id`,
+			limits: transformLimits{timeout: time.Minute, memory: 64 << 20},
+			want:   want{data: `["a","b"]`},
+		},
+		"ProcessError": {
+			reason: "Errors evaluating a transform in its own process should be returned.",
+			src:    `error("boom")`,
+			limits: transformLimits{timeout: time.Minute, memory: 64 << 20},
+			want: want{
+				data: data,
+				err:  errors.Wrap(errors.New("error: boom"), errTransformData),
+			},
+		},
+		"ProcessTimeout": {
+			reason: "Transforms evaluated in their own process that exceed their timeout should be cancelled.",
+			src:    `last(range(1e12))`,
+			limits: transformLimits{timeout: 500 * time.Millisecond, memory: 64 << 20},
+			want: want{
+				data: data,
+				err:  errors.Wrap(errors.Errorf(errFmtTransformTimeout, 500*time.Millisecond), errTransformData),
+			},
+		},
+		"MemoryLimit": {
+			reason: "Transforms that exceed their memory limit should be stopped, even if their output is small.",
+			src:    `[range(1e8)] | length`,
+			limits: transformLimits{timeout: time.Minute, memory: 64 << 20},
+			want: want{
+				data: data,
+				err:  errors.Wrap(errors.Errorf(errFmtTransformMemory, 64<<20), errTransformData),
+			},
+		},
+		"InputLimit": {
+			reason: "Data larger than the memory limit should not be transformed.",
+			src:    `.`,
+			limits: transformLimits{timeout: time.Minute, memory: 16},
+			want: want{
+				data: data,
+				err:  errors.Wrap(errors.Errorf(errFmtTransformInput, len(data), 16), errTransformData),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tf, err := compileTransform(tc.src, tc.limits)
+			if err != nil {
+				t.Fatalf("compileTransform(...): %v", err)
+			}
+			re := &runtime.RawExtension{Raw: []byte(data)}
+			err = tf.apply(context.Background(), re)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\napply(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.data, string(re.Raw)); diff != "" {
+				t.Errorf("\n%s\napply(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompileTransform(t *testing.T) {
+	if _, err := compileTransform(`.clusters[`, transformLimits{}); err == nil {
+		t.Errorf("compileTransform(...): want error compiling an invalid program")
+	}
+	if _, err := compileTransform(`undefined_function(1)`, transformLimits{}); err == nil {
+		t.Errorf("compileTransform(...): want error compiling a program calling an undefined function")
+	}
+}
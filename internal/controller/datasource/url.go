@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errURI              = "uri must be specified when type is uri"
+	errBodyFrom         = "cannot resolve request body"
+	errFmtHeaderFrom    = "cannot resolve request header %s"
+	errFmtRequestFailed = "request failed: %s"
+)
+
+// validators identify a version of a URL response, allowing subsequent
+// requests for it to be made conditionally.
+type validators struct {
+	etag         string
+	lastModified string
+
+	// source is the index of the source of a DataSource that the
+	// validators were recorded for.
+	source int
+}
+
+func (v validators) empty() bool {
+	return v.etag == "" && v.lastModified == ""
+}
+
+// matches returns true if the supplied validators identify the same version
+// of a response as these validators.
+func (v validators) matches(o validators) bool {
+	if v.etag != "" || o.etag != "" {
+		return v.etag == o.etag
+	}
+	return v.lastModified != "" && v.lastModified == o.lastModified
+}
+
+// buildRequest returns the request to make to the supplied URL, resolving any
+// header or body values that are read from ConfigMaps or Secrets.
+func buildRequest(ctx context.Context, kube client.Client, namespace string, uri string, hr *v1alpha1.HTTPRequest) (request, error) {
+	r := request{
+		Method:  http.MethodGet,
+		URL:     uri,
+		Headers: map[string]string{"Accept": "application/json"},
+	}
+	if hr == nil {
+		return r, nil
+	}
+
+	if hr.Method != nil {
+		r.Method = *hr.Method
+	}
+	r.Query = hr.QueryParameters
+
+	for k, v := range hr.Headers {
+		r.Headers[http.CanonicalHeaderKey(k)] = v
+	}
+	for _, h := range hr.HeadersFrom {
+		v, err := resolveValue(ctx, kube, namespace, h.ValueFrom)
+		if err != nil {
+			return request{}, errors.Wrapf(err, errFmtHeaderFrom, h.Name)
+		}
+		r.Headers[http.CanonicalHeaderKey(h.Name)] = v
+	}
+
+	if hr.Body != nil {
+		r.Body = *hr.Body
+	}
+	if hr.BodyFrom != nil {
+		v, err := resolveValue(ctx, kube, namespace, *hr.BodyFrom)
+		if err != nil {
+			return request{}, errors.Wrap(err, errBodyFrom)
+		}
+		r.Body = v
+	}
+	if _, ok := r.Headers["Content-Type"]; r.Body != "" && !ok {
+		r.Headers["Content-Type"] = "application/json"
+	}
+
+	return r, nil
+}
+
+func lookupURL(ctx context.Context, cache *responseCache, r request, bypass bool, d decoder, v validators, re *runtime.RawExtension) (lookupResult, error) { //nolint:interfacer
+	// Interfacer linting disabled as it tries to suggest json.Unmarshaler
+	var res *response
+	var err error
+	if bypass {
+		res, err = doRequest(ctx, r, v)
+	} else {
+		res, err = cache.Fetch(r, v, func(cv validators) (*response, error) { return doRequest(ctx, r, cv) })
+	}
+	if err != nil {
+		return lookupResult{}, err
+	}
+
+	// A not modified response to a request coalesced with that of another
+	// DataSource may have been made using that DataSource's validators, and
+	// says nothing about whether our data has been modified.
+	if res.notModified && !v.matches(res.validators) {
+		if res, err = doRequest(ctx, r, validators{}); err != nil {
+			return lookupResult{}, err
+		}
+	}
+
+	// Responses served from the cache may have been revalidated using the
+	// cache's own validators, so we compare them to ours to determine
+	// whether the response differs from the data we already have.
+	if res.notModified || (!v.empty() && v.matches(res.validators)) {
+		return lookupResult{notModified: true, validators: v}, nil
+	}
+
+	return lookupResult{validators: res.validators}, d.decodeBody(res, re)
+}
+
+// decodeBody writes the body of the supplied response to re. Bodies are
+// parsed as JSON if no format is configured, and the format is detected from
+// the response's Content-Type if it is auto.
+func (d decoder) decodeBody(res *response, re *runtime.RawExtension) error {
+	format := v1alpha1.FormatJSON
+	if d.format != nil {
+		format = *d.format
+	}
+	if format == v1alpha1.FormatAuto {
+		format = formatForContentType(res.contentType)
+	}
+	var v interface{}
+	switch format {
+	case v1alpha1.FormatJSON:
+		return re.UnmarshalJSON(res.body)
+	case v1alpha1.FormatRaw:
+		v = d.rawValue(res.body, res.contentType)
+	default:
+		var err error
+		if v, err = d.parse(format, res.body); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return re.UnmarshalJSON(b)
+}
+
+// doRequest makes the supplied request, returning an error if the response
+// is not successful. The request is made conditionally if any validators are
+// supplied, in which case the response may be not modified and have no body.
+// Requests authenticated using OAuth2 access tokens are retried once with a
+// fresh token if the token is rejected.
+func doRequest(ctx context.Context, r request, v validators) (*response, error) {
+	c := r.client
+	if c == nil {
+		c = newRestyClient(nil, nil)
+	}
+
+	res, tok, err := execute(ctx, c, r, v)
+	if err == nil && tok != nil && res.StatusCode() == http.StatusUnauthorized {
+		r.tokens.Invalidate(tok)
+		res, _, err = execute(ctx, c, r, v)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() == http.StatusNotModified && !v.empty() {
+		return &response{notModified: true, validators: v}, nil
+	}
+
+	if !res.IsSuccess() {
+		return nil, errors.Errorf(errFmtRequestFailed, res.Status())
+	}
+
+	return &response{
+		body:        res.Body(),
+		contentType: res.Header().Get("Content-Type"),
+		validators: validators{
+			etag:         res.Header().Get("ETag"),
+			lastModified: res.Header().Get("Last-Modified"),
+		},
+	}, nil
+}
+
+// execute makes the supplied request using the supplied client, returning the
+// OAuth2 access token it was authenticated with, if any.
+func execute(ctx context.Context, c *resty.Client, r request, v validators) (*resty.Response, *oauth2.Token, error) {
+	req := c.R().
+		SetContext(ctx).
+		SetHeaders(r.Headers).
+		SetQueryParams(r.Query)
+	if r.Body != "" {
+		req.SetBody(r.Body)
+	}
+	if v.etag != "" {
+		req.SetHeader("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.SetHeader("If-Modified-Since", v.lastModified)
+	}
+
+	var tok *oauth2.Token
+	if r.tokens != nil {
+		// Access tokens are retrieved using the same client as the
+		// request, so that its TLS configuration and URL policy apply.
+		var err error
+		if tok, err = r.tokens.Token(context.WithValue(ctx, oauth2.HTTPClient, c.GetClient())); err != nil {
+			return nil, nil, err
+		}
+		req.SetAuthToken(tok.AccessToken)
+	}
+
+	res, err := req.Execute(r.Method, r.URL)
+	return res, tok, err
+}
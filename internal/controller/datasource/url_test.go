@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+func TestLookupURL(t *testing.T) {
+	// ifNoneMatch records the If-None-Match header of the last request.
+	ifNoneMatch := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		w.Header().Set("ETag", `"v2"`)
+		if r.Header.Get("If-None-Match") == `"v2"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":2}`))
+	}))
+	defer srv.Close()
+
+	type args struct {
+		cache  *responseCache
+		bypass bool
+		v      validators
+	}
+
+	type want struct {
+		res         lookupResult
+		re          *runtime.RawExtension
+		ifNoneMatch string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unconditional": {
+			reason: "The response body and validators should be returned when no validators are supplied.",
+			args: args{
+				bypass: true,
+			},
+			want: want{
+				res: lookupResult{validators: validators{etag: `"v2"`}},
+				re:  &runtime.RawExtension{Raw: []byte(`{"version":2}`)},
+			},
+		},
+		"NotModified": {
+			reason: "A 304 response to a conditional request should be reported as not modified.",
+			args: args{
+				bypass: true,
+				v:      validators{etag: `"v2"`},
+			},
+			want: want{
+				res:         lookupResult{notModified: true, validators: validators{etag: `"v2"`}},
+				re:          &runtime.RawExtension{},
+				ifNoneMatch: `"v2"`,
+			},
+		},
+		"Modified": {
+			reason: "The response body should be returned when the validators no longer match.",
+			args: args{
+				bypass: true,
+				v:      validators{etag: `"v1"`},
+			},
+			want: want{
+				res:         lookupResult{validators: validators{etag: `"v2"`}},
+				re:          &runtime.RawExtension{Raw: []byte(`{"version":2}`)},
+				ifNoneMatch: `"v1"`,
+			},
+		},
+		"CachedNotModified": {
+			reason: "The supplied validators should be sent through a cache with no response to revalidate, and a 304 response reported as not modified.",
+			args: args{
+				cache: newResponseCache(time.Minute),
+				v:     validators{etag: `"v2"`},
+			},
+			want: want{
+				res:         lookupResult{notModified: true, validators: validators{etag: `"v2"`}},
+				re:          &runtime.RawExtension{},
+				ifNoneMatch: `"v2"`,
+			},
+		},
+		"CachedModified": {
+			reason: "The response body should be returned through the cache when the supplied validators no longer match.",
+			args: args{
+				cache: newResponseCache(time.Minute),
+				v:     validators{etag: `"v1"`},
+			},
+			want: want{
+				res:         lookupResult{validators: validators{etag: `"v2"`}},
+				re:          &runtime.RawExtension{Raw: []byte(`{"version":2}`)},
+				ifNoneMatch: `"v1"`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ifNoneMatch = ""
+			re := &runtime.RawExtension{}
+			res, err := lookupURL(context.Background(), tc.args.cache, request{Method: http.MethodGet, URL: srv.URL}, tc.args.bypass, decoder{}, tc.args.v, re)
+			if err != nil {
+				t.Fatalf("\n%s\nlookupURL(...): unexpected error: %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.res, res, cmp.AllowUnexported(lookupResult{}, validators{})); diff != "" {
+				t.Errorf("\n%s\nlookupURL(...): -want result, +got result:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.re, re); diff != "" {
+				t.Errorf("\n%s\nlookupURL(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ifNoneMatch, ifNoneMatch); diff != "" {
+				t.Errorf("\n%s\nlookupURL(...): -want If-None-Match, +got If-None-Match:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	secret := test.NewMockGetFn(nil, func(obj client.Object) error {
+		obj.(*apiv1.Secret).Data = map[string][]byte{"tenant": []byte("payments")}
+		return nil
+	})
+
+	type args struct {
+		kube client.Client
+		hr   *v1alpha1.HTTPRequest
+	}
+
+	type want struct {
+		r   request
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Default": {
+			reason: "A GET request accepting JSON should be made when no request is configured.",
+			want: want{
+				r: request{
+					Method:  http.MethodGet,
+					URL:     "https://example.org",
+					Headers: map[string]string{"Accept": "application/json"},
+				},
+			},
+		},
+		"Configured": {
+			reason: "The configured method, headers, query parameters and body should be used.",
+			args: args{
+				kube: &test.MockClient{MockGet: secret},
+				hr: &v1alpha1.HTTPRequest{
+					Method:          pointer.StringPtr(http.MethodPost),
+					Headers:         map[string]string{"accept": "application/graphql+json"},
+					QueryParameters: map[string]string{"region": "eu"},
+					HeadersFrom: []v1alpha1.HeaderSource{{
+						Name:      "X-Tenant-ID",
+						ValueFrom: v1alpha1.ValueSource{SecretKeyRef: &v1alpha1.KeyReference{Name: "tenant", Key: "tenant"}},
+					}},
+					Body: pointer.StringPtr(`{"query":"{ regions }"}`),
+				},
+			},
+			want: want{
+				r: request{
+					Method: http.MethodPost,
+					URL:    "https://example.org",
+					Query:  map[string]string{"region": "eu"},
+					Headers: map[string]string{
+						"Accept":       "application/graphql+json",
+						"Content-Type": "application/json",
+						"X-Tenant-Id":  "payments",
+					},
+					Body: `{"query":"{ regions }"}`,
+				},
+			},
+		},
+		"BodyFromError": {
+			reason: "Errors resolving the body should be returned.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				hr: &v1alpha1.HTTPRequest{
+					BodyFrom: &v1alpha1.ValueSource{ConfigMapKeyRef: &v1alpha1.KeyReference{Name: "query", Key: "query"}},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Wrap(errBoom, errGetValueSource), errBodyFrom),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, err := buildRequest(context.Background(), tc.args.kube, "test", "https://example.org", tc.args.hr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nbuildRequest(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, r, cmp.AllowUnexported(request{})); diff != "" {
+				t.Errorf("\n%s\nbuildRequest(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+)
+
+const (
+	errValueSource    = "exactly one of configMapKeyRef or secretKeyRef must be specified"
+	errGetValueSource = "cannot get value source"
+	errFmtKeyNotFound = "%s %s does not contain key %s"
+)
+
+// resolveValue reads the value selected by the supplied ValueSource from a
+// ConfigMap or Secret in the supplied namespace.
+func resolveValue(ctx context.Context, kube client.Client, namespace string, vs v1alpha1.ValueSource) (string, error) {
+	switch {
+	case vs.ConfigMapKeyRef != nil && vs.SecretKeyRef == nil:
+		ref := vs.ConfigMapKeyRef
+		cm := &apiv1.ConfigMap{}
+		if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, cm); err != nil {
+			return "", errors.Wrap(err, errGetValueSource)
+		}
+		if v, ok := cm.Data[ref.Key]; ok {
+			return v, nil
+		}
+		if v, ok := cm.BinaryData[ref.Key]; ok {
+			return string(v), nil
+		}
+		return "", errors.Errorf(errFmtKeyNotFound, kindConfigMap, ref.Name, ref.Key)
+
+	case vs.SecretKeyRef != nil && vs.ConfigMapKeyRef == nil:
+		ref := vs.SecretKeyRef
+		s := &apiv1.Secret{}
+		if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, s); err != nil {
+			return "", errors.Wrap(err, errGetValueSource)
+		}
+		if v, ok := s.Data[ref.Key]; ok {
+			return string(v), nil
+		}
+		return "", errors.Errorf(errFmtKeyNotFound, kindSecret, ref.Name, ref.Key)
+	}
+
+	return "", errors.New(errValueSource)
+}
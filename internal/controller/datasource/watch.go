@@ -0,0 +1,265 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+const (
+	// referenceIndex is the name of the field index of DataSources by the
+	// ConfigMaps, Secrets and DataSources they reference.
+	referenceIndex = "dataSourceReferences"
+
+	// anyName is used in place of an object name by references that select
+	// objects by label.
+	anyName = "*"
+
+	kindConfigMap  = "ConfigMap"
+	kindSecret     = "Secret"
+	kindDataSource = "DataSource"
+
+	errListDataSources = "cannot list DataSources referencing object"
+	errGetPCNamespace  = "cannot get ProviderConfig namespace of DataSource"
+)
+
+// A reference is a ConfigMap, Secret or DataSource that a DataSource
+// retrieves data from.
+type reference struct {
+	kind      string
+	name      string
+	namespace *string
+	selector  *metav1.LabelSelector
+}
+
+func referenceKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// referencesOf returns the ConfigMaps, Secrets and DataSources referenced by
+// the supplied DataSource. Sources are rendered using the DataSource's
+// resolved variables where possible.
+func referencesOf(ds *v1alpha1.DataSource) []reference {
+	p := ds.Spec.ForProvider
+	refs := []reference{}
+	for _, src := range sourcesOf(p) {
+		if rendered, err := renderSource(p, src); err == nil {
+			src = rendered
+		}
+		refs = append(refs, sourceReferences(src)...)
+	}
+
+	// DataSources are cluster scoped.
+	cluster := ""
+	for _, v := range p.Variables {
+		switch {
+		case v.DataSourceRef != nil:
+			refs = append(refs, reference{kind: kindDataSource, name: v.DataSourceRef.Name, namespace: &cluster})
+		case v.DataSourceSelector != nil:
+			s := &metav1.LabelSelector{MatchLabels: v.DataSourceSelector.MatchLabels}
+			refs = append(refs, reference{kind: kindDataSource, name: anyName, namespace: &cluster, selector: s})
+		}
+	}
+	return refs
+}
+
+// sourceReferences returns the ConfigMaps and Secrets referenced by the
+// supplied source.
+func sourceReferences(p v1alpha1.SourceParameters) []reference {
+	refs := []reference{}
+
+	switch p.SourceType {
+	case v1alpha1.SourceTypeConfigMap:
+		switch {
+		case p.ConfigMapName != nil:
+			refs = append(refs, reference{kind: kindConfigMap, name: *p.ConfigMapName})
+		case p.ConfigMapSelector != nil:
+			refs = append(refs, reference{kind: kindConfigMap, name: anyName, selector: p.ConfigMapSelector})
+		}
+	case v1alpha1.SourceTypeSecret:
+		if p.SecretName != nil {
+			refs = append(refs, reference{kind: kindSecret, name: *p.SecretName})
+		}
+	case v1alpha1.SourceTypeKubernetes:
+		if p.Object == nil {
+			break
+		}
+		gvk := schema.FromAPIVersionAndKind(p.Object.APIVersion, p.Object.Kind)
+		if gvk.Group != "" || gvk.Kind != kindConfigMap {
+			break
+		}
+		r := reference{kind: kindConfigMap, name: p.Object.Name, namespace: p.Object.Namespace}
+		if r.name == "" {
+			r.name = anyName
+			r.selector = p.Object.Selector
+		}
+		refs = append(refs, r)
+	}
+
+	if p.Request != nil {
+		for _, h := range p.Request.HeadersFrom {
+			refs = append(refs, valueReferences(h.ValueFrom)...)
+		}
+		if p.Request.BodyFrom != nil {
+			refs = append(refs, valueReferences(*p.Request.BodyFrom)...)
+		}
+	}
+
+	return refs
+}
+
+// valueReferences returns the ConfigMaps and Secrets referenced by the
+// supplied ValueSource.
+func valueReferences(vs v1alpha1.ValueSource) []reference {
+	refs := []reference{}
+	if vs.ConfigMapKeyRef != nil {
+		refs = append(refs, reference{kind: kindConfigMap, name: vs.ConfigMapKeyRef.Name})
+	}
+	if vs.SecretKeyRef != nil {
+		refs = append(refs, reference{kind: kindSecret, name: vs.SecretKeyRef.Name})
+	}
+	return refs
+}
+
+// indexReferences is a client.IndexerFunc that indexes a DataSource by the
+// ConfigMaps, Secrets and DataSources it references.
+func indexReferences(o client.Object) []string {
+	ds, ok := o.(*v1alpha1.DataSource)
+	if !ok {
+		return nil
+	}
+	refs := referencesOf(ds)
+	keys := make([]string, len(refs))
+	for i, r := range refs {
+		keys[i] = referenceKey(r.kind, r.name)
+	}
+	return keys
+}
+
+// A referenceMapper maps a ConfigMap, Secret or DataSource to the DataSources
+// that reference it, so that changes to the object are reflected promptly
+// rather than on the next poll.
+type referenceMapper struct {
+	kube client.Client
+	kind string
+	log  logging.Logger
+}
+
+// Map returns a reconcile request for every DataSource that references the
+// supplied object.
+func (m *referenceMapper) Map(o client.Object) []reconcile.Request {
+	ctx := context.Background()
+	reqs := []reconcile.Request{}
+
+	for _, key := range []string{referenceKey(m.kind, o.GetName()), referenceKey(m.kind, anyName)} {
+		l := &v1alpha1.DataSourceList{}
+		if err := m.kube.List(ctx, l, client.MatchingFields{referenceIndex: key}); err != nil {
+			m.log.Debug(errListDataSources, "error", err, "key", key)
+			continue
+		}
+		for i := range l.Items {
+			if m.references(ctx, &l.Items[i], o) {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: l.Items[i].GetName()}})
+			}
+		}
+	}
+
+	return reqs
+}
+
+// references returns true if the supplied DataSource references the supplied
+// object. DataSources are indexed by object name only, so the namespace and
+// any label selector of each reference must be checked here.
+func (m *referenceMapper) references(ctx context.Context, ds *v1alpha1.DataSource, o client.Object) bool {
+	for _, r := range referencesOf(ds) {
+		if r.kind != m.kind {
+			continue
+		}
+		if r.name != anyName && r.name != o.GetName() {
+			continue
+		}
+		if r.name == anyName {
+			s, err := selectorFor(r.selector)
+			if err != nil || !s.Matches(labels.Set(o.GetLabels())) {
+				continue
+			}
+		}
+
+		ns, err := m.namespaceOf(ctx, ds, r)
+		if err != nil {
+			m.log.Debug(errGetPCNamespace, "error", err, "name", ds.GetName())
+			continue
+		}
+		if ns == o.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceOf returns the namespace a reference is resolved in; either the
+// namespace of the reference itself or that of the DataSource's
+// ProviderConfig.
+func (m *referenceMapper) namespaceOf(ctx context.Context, ds *v1alpha1.DataSource, r reference) (string, error) {
+	if r.namespace != nil {
+		return *r.namespace, nil
+	}
+	ref := ds.GetProviderConfigReference()
+	if ref == nil {
+		return "", nil
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := m.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return "", err
+	}
+	return pc.Spec.Namespace, nil
+}
+
+// A dataChangedPredicate passes updates to DataSources whose data changed.
+// DataSources write their own status on every reconcile, so DataSources that
+// reference them need only be reconciled when their data changes.
+type dataChangedPredicate struct {
+	predicate.Funcs
+}
+
+// Update returns true if the data of the updated DataSource changed.
+func (dataChangedPredicate) Update(e event.UpdateEvent) bool {
+	o, ok := e.ObjectOld.(*v1alpha1.DataSource)
+	if !ok {
+		return false
+	}
+	n, ok := e.ObjectNew.(*v1alpha1.DataSource)
+	if !ok {
+		return false
+	}
+	return !cmp.Equal(o.Status.AtProvider, n.Status.AtProvider)
+}
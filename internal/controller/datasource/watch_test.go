@@ -0,0 +1,297 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/benagricola/provider-externaldata/apis/datasource/v1alpha1"
+	apisv1alpha1 "github.com/benagricola/provider-externaldata/apis/v1alpha1"
+)
+
+func dataSource(name string, p v1alpha1.DataSourceParameters) v1alpha1.DataSource {
+	ds := v1alpha1.DataSource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.DataSourceSpec{ForProvider: p},
+	}
+	ds.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+	return ds
+}
+
+func TestIndexReferences(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		want   []string
+	}{
+		"NotDataSource": {
+			reason: "Objects that are not DataSources should not be indexed.",
+			o:      &apiv1.ConfigMap{},
+		},
+		"ConfigMapName": {
+			reason: "A DataSource should be indexed by the name of the ConfigMap it references.",
+			o: func() client.Object {
+				ds := dataSource("cm", v1alpha1.DataSourceParameters{
+					SourceParameters: v1alpha1.SourceParameters{
+						SourceType:    v1alpha1.SourceTypeConfigMap,
+						ConfigMapName: pointer.StringPtr("my-values"),
+					},
+				})
+				return &ds
+			}(),
+			want: []string{"ConfigMap/my-values"},
+		},
+		"ConfigMapSelector": {
+			reason: "A DataSource using a selector should be indexed under any name.",
+			o: func() client.Object {
+				ds := dataSource("cm", v1alpha1.DataSourceParameters{
+					SourceParameters: v1alpha1.SourceParameters{
+						SourceType:        v1alpha1.SourceTypeConfigMap,
+						ConfigMapSelector: &metav1.LabelSelector{},
+					},
+				})
+				return &ds
+			}(),
+			want: []string{"ConfigMap/*"},
+		},
+		"Secret": {
+			reason: "A DataSource should be indexed by the name of the Secret it references.",
+			o: func() client.Object {
+				ds := dataSource("secret", v1alpha1.DataSourceParameters{
+					SourceParameters: v1alpha1.SourceParameters{
+						SourceType: v1alpha1.SourceTypeSecret,
+						SecretName: pointer.StringPtr("creds"),
+					},
+				})
+				return &ds
+			}(),
+			want: []string{"Secret/creds"},
+		},
+		"RequestValues": {
+			reason: "A DataSource should be indexed by the ConfigMaps and Secrets its request reads values from.",
+			o: func() client.Object {
+				ds := dataSource("request", v1alpha1.DataSourceParameters{
+					SourceParameters: v1alpha1.SourceParameters{
+						SourceType: v1alpha1.SourceTypeURL,
+						URL:        pointer.StringPtr("https://example.org"),
+						Request: &v1alpha1.HTTPRequest{
+							HeadersFrom: []v1alpha1.HeaderSource{{
+								Name:      "X-Tenant-ID",
+								ValueFrom: v1alpha1.ValueSource{SecretKeyRef: &v1alpha1.KeyReference{Name: "tenant", Key: "id"}},
+							}},
+							BodyFrom: &v1alpha1.ValueSource{ConfigMapKeyRef: &v1alpha1.KeyReference{Name: "query", Key: "query"}},
+						},
+					},
+				})
+				return &ds
+			}(),
+			want: []string{"Secret/tenant", "ConfigMap/query"},
+		},
+		"Sources": {
+			reason: "A DataSource should be indexed by the objects referenced by each of its sources.",
+			o: func() client.Object {
+				ds := dataSource("sources", v1alpha1.DataSourceParameters{
+					Sources: []v1alpha1.SourceParameters{
+						{SourceType: v1alpha1.SourceTypeURL, URL: pointer.StringPtr("https://example.org")},
+						{SourceType: v1alpha1.SourceTypeConfigMap, ConfigMapName: pointer.StringPtr("defaults")},
+					},
+				})
+				return &ds
+			}(),
+			want: []string{"ConfigMap/defaults"},
+		},
+		"Variables": {
+			reason: "A DataSource should be indexed by the DataSources its variables reference, and by its rendered ConfigMap name.",
+			o: func() client.Object {
+				ds := dataSource("variables", v1alpha1.DataSourceParameters{
+					SourceParameters: v1alpha1.SourceParameters{
+						SourceType:    v1alpha1.SourceTypeConfigMap,
+						ConfigMapName: pointer.StringPtr("{{ .tenant }}-values"),
+					},
+					Variables: []v1alpha1.Variable{
+						{Name: "tenant", DataSourceRef: &xpv1.Reference{Name: "tenant"}, Value: pointer.StringPtr("payments")},
+						{Name: "region", DataSourceSelector: &xpv1.Selector{MatchLabels: map[string]string{"kind": "region"}}, Value: pointer.StringPtr("eu-west-1")},
+					},
+				})
+				return &ds
+			}(),
+			want: []string{"ConfigMap/payments-values", "DataSource/tenant", "DataSource/*"},
+		},
+		"URL": {
+			reason: "A DataSource that references no cluster objects should not be indexed.",
+			o: func() client.Object {
+				ds := dataSource("url", v1alpha1.DataSourceParameters{
+					SourceParameters: v1alpha1.SourceParameters{
+						SourceType: v1alpha1.SourceTypeURL,
+						URL:        pointer.StringPtr("https://example.org"),
+					},
+				})
+				return &ds
+			}(),
+			want: []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := indexReferences(tc.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nindexReferences(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReferenceMapperMap(t *testing.T) {
+	named := dataSource("named", v1alpha1.DataSourceParameters{
+		SourceParameters: v1alpha1.SourceParameters{
+			SourceType:    v1alpha1.SourceTypeConfigMap,
+			ConfigMapName: pointer.StringPtr("my-values"),
+		},
+	})
+	selected := dataSource("selected", v1alpha1.DataSourceParameters{
+		SourceParameters: v1alpha1.SourceParameters{
+			SourceType:        v1alpha1.SourceTypeConfigMap,
+			ConfigMapSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+		},
+	})
+
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.(*apisv1alpha1.ProviderConfig).Spec.Namespace = "test"
+			return nil
+		}),
+		MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			l := obj.(*v1alpha1.DataSourceList)
+			switch lo.FieldSelector.String() {
+			case referenceIndex + "=ConfigMap/my-values":
+				l.Items = []v1alpha1.DataSource{named}
+			case referenceIndex + "=ConfigMap/*":
+				l.Items = []v1alpha1.DataSource{selected}
+			}
+			return nil
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		want   []reconcile.Request
+	}{
+		"NamedAndSelected": {
+			reason: "DataSources referencing the object by name or by matching selector should be enqueued.",
+			o: &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "my-values",
+				Labels:    map[string]string{"team": "payments"},
+			}},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "named"}},
+				{NamespacedName: types.NamespacedName{Name: "selected"}},
+			},
+		},
+		"SelectorMismatch": {
+			reason: "DataSources whose selector does not match the object should not be enqueued.",
+			o: &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "other",
+				Labels:    map[string]string{"team": "identity"},
+			}},
+			want: []reconcile.Request{},
+		},
+		"OtherNamespace": {
+			reason: "DataSources should not be enqueued for objects outside their ProviderConfig namespace.",
+			o: &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "other",
+				Name:      "my-values",
+				Labels:    map[string]string{"team": "payments"},
+			}},
+			want: []reconcile.Request{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &referenceMapper{kube: kube, kind: kindConfigMap, log: logging.NewNopLogger()}
+			got := m.Map(tc.o)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nm.Map(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDataChangedPredicateUpdate(t *testing.T) {
+	data := func(raw string) *v1alpha1.DataSource {
+		ds := dataSource("ds", v1alpha1.DataSourceParameters{})
+		ds.Status.AtProvider = &runtime.RawExtension{Raw: []byte(raw)}
+		return &ds
+	}
+	refreshed := func(ds *v1alpha1.DataSource) *v1alpha1.DataSource {
+		now := metav1.Now()
+		ds.Status.LastRefreshTime = &now
+		return ds
+	}
+
+	cases := map[string]struct {
+		reason string
+		e      event.UpdateEvent
+		want   bool
+	}{
+		"DataChanged": {
+			reason: "Updates that change the data of a DataSource should pass.",
+			e:      event.UpdateEvent{ObjectOld: data(`{"a":1}`), ObjectNew: data(`{"a":2}`)},
+			want:   true,
+		},
+		"StatusChanged": {
+			reason: "Updates that change only the rest of the status of a DataSource should not pass.",
+			e:      event.UpdateEvent{ObjectOld: data(`{"a":1}`), ObjectNew: refreshed(data(`{"a":1}`))},
+			want:   false,
+		},
+		"NotDataSource": {
+			reason: "Updates to other objects should not pass.",
+			e:      event.UpdateEvent{ObjectOld: &apiv1.ConfigMap{}, ObjectNew: &apiv1.ConfigMap{}},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := dataChangedPredicate{}.Update(tc.e)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
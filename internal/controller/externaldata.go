@@ -28,10 +28,12 @@ import (
 
 // Setup creates all ExternalData controllers with the supplied logger and adds them to
 // the supplied manager.
-func Setup(mgr ctrl.Manager, l logging.Logger, wl workqueue.RateLimiter) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, wl workqueue.RateLimiter, do datasource.Options) error {
 	for _, setup := range []func(ctrl.Manager, logging.Logger, workqueue.RateLimiter) error{
 		config.Setup,
-		datasource.Setup,
+		func(mgr ctrl.Manager, l logging.Logger, wl workqueue.RateLimiter) error {
+			return datasource.Setup(mgr, l, wl, do)
+		},
 	} {
 		if err := setup(mgr, l, wl); err != nil {
 			return err